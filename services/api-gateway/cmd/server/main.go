@@ -11,6 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/api-gateway/internal/auth"
 	"github.com/yourusername/go-microservices/api-gateway/internal/config"
 	"github.com/yourusername/go-microservices/api-gateway/internal/handlers"
 	"github.com/yourusername/go-microservices/api-gateway/internal/middleware"
@@ -29,7 +30,12 @@ func main() {
 
 	// Initialize router
 	router := gin.New()
+	// X-Forwarded-For is only believed from known load balancers in front of the gateway
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 	router.Use(gin.Recovery())
+	router.Use(middleware.RequestID())
 	router.Use(middleware.Logger(logger))
 	router.Use(middleware.PrometheusMetrics())
 
@@ -44,20 +50,88 @@ func main() {
 	// Prometheus metrics endpoint
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	userProxy, err := handlers.NewProxy(cfg.UserServiceURL, cfg.ServiceToken, logger)
+	if err != nil {
+		logger.Fatalf("Invalid user service URL: %v", err)
+	}
+
+	inboxHandler, err := handlers.NewInboxHandler(cfg.NotificationServiceURL, cfg.ServiceToken, logger)
+	if err != nil {
+		logger.Fatalf("Invalid notification service URL: %v", err)
+	}
+
+	introspector := auth.NewIntrospector(cfg.UserServiceURL, cfg.ServiceToken, cfg.AuthCacheTTL)
+
+	// Routes that exchange credentials, reachable with an expired access token
+	publicGroup := router.Group("/api", middleware.Anonymous())
+	{
+		publicGroup.POST("/verify-email", userProxy)
+		publicGroup.POST("/auth/login", userProxy)
+		publicGroup.POST("/auth/refresh", userProxy)
+		publicGroup.POST("/auth/logout", userProxy)
+		publicGroup.POST("/auth/password-reset", userProxy)
+		publicGroup.POST("/auth/password-reset/confirm", userProxy)
+		publicGroup.POST("/auth/mfa/verify", userProxy)
+	}
+
 	// API routes
-	apiGroup := router.Group("/api")
+	apiGroup := router.Group("/api", middleware.Authenticate(introspector, logger))
 	{
 		// User service routes
-		userHandler := handlers.NewUserHandler(cfg.UserServiceURL, logger)
-		apiGroup.GET("/users", userHandler.GetUsers)
-		apiGroup.GET("/users/:id", userHandler.GetUser)
-		apiGroup.POST("/users", userHandler.CreateUser)
+		userHandler := handlers.NewUserHandler(cfg.UserServiceURL, cfg.ServiceToken, logger)
+		apiGroup.GET("/users", middleware.RequireScope("users:read"), userHandler.GetUsers)
+		apiGroup.GET("/users/:id", middleware.RequireScope("users:read"), userHandler.GetUser)
+		apiGroup.GET("/users/:id/history", middleware.RequireIdentity(), userProxy)
+		apiGroup.POST("/users/:id/export", userProxy)
+		apiGroup.POST("/users/:id/erase", userProxy)
+		apiGroup.POST("/users", middleware.RequireScope("users:write"), userHandler.CreateUser)
+		apiGroup.POST("/users:action", middleware.RequireActionScope(map[string]string{
+			":batchGet": "users:read",
+			":batch":    "users:write",
+		}), middleware.RequireActionIdentity(":batch"), userProxy)
+		apiGroup.GET("/users/export", userProxy)
+		apiGroup.POST("/users/import", userProxy)
+		apiGroup.GET("/users/import/:job_id", userProxy)
+		apiGroup.GET("/users/import/:job_id/errors", userProxy)
+		apiGroup.POST("/users/:id/verify-email/send", middleware.RequireIdentity(), userProxy)
+
+		// Authenticated account routes
+		apiGroup.POST("/auth/mfa/enroll", userProxy)
+		apiGroup.POST("/auth/mfa/confirm", userProxy)
+		apiGroup.DELETE("/admin/users/:id/mfa", userProxy)
+		apiGroup.POST("/admin/users/:id/unlock", userProxy)
+		apiGroup.GET("/api-keys", userProxy)
+		apiGroup.POST("/api-keys", userProxy)
+		apiGroup.DELETE("/api-keys/:id", userProxy)
+		apiGroup.GET("/sessions", userProxy)
+		apiGroup.DELETE("/sessions", userProxy)
+		apiGroup.DELETE("/sessions/:session_id", userProxy)
+		apiGroup.GET("/admin/users/:id/sessions", userProxy)
+		apiGroup.DELETE("/admin/users/:id/sessions", userProxy)
+		apiGroup.DELETE("/admin/users/:id/sessions/:session_id", userProxy)
+		apiGroup.GET("/audit", userProxy)
+		apiGroup.GET("/audit/verify", userProxy)
 
 		// Product service routes
 		productHandler := handlers.NewProductHandler(cfg.ProductServiceURL, logger)
-		apiGroup.GET("/products", productHandler.GetProducts)
-		apiGroup.GET("/products/:id", productHandler.GetProduct)
-		apiGroup.POST("/products", productHandler.CreateProduct)
+		apiGroup.GET("/products", middleware.RequireScope("products:read"), productHandler.GetProducts)
+		apiGroup.GET("/products/:id", middleware.RequireScope("products:read"), productHandler.GetProduct)
+		apiGroup.POST("/products", middleware.RequireScope("products:write"), productHandler.CreateProduct)
+
+		// Notification service inbox routes, which act for the authenticated user
+		inboxGroup := apiGroup.Group("/inbox", middleware.RequireIdentity())
+		inboxRead := middleware.RequireScope("inbox:read")
+		inboxWrite := middleware.RequireScope("inbox:write")
+		inboxGroup.GET("", inboxRead, inboxHandler.Proxy)
+		inboxGroup.GET("/unread-count", inboxRead, inboxHandler.Proxy)
+		inboxGroup.POST("/read-all", inboxWrite, inboxHandler.Proxy)
+		inboxGroup.POST("/:id/read", inboxWrite, inboxHandler.Proxy)
+		inboxGroup.GET("/stream", inboxRead, inboxHandler.Proxy)
+		inboxGroup.GET("/ws", inboxRead, inboxHandler.WebSocket)
+
+		// Notification preferences, which the notification service only shows their own user
+		apiGroup.GET("/users/:id/preferences", middleware.RequireIdentity(), inboxRead, inboxHandler.Proxy)
+		apiGroup.PUT("/users/:id/preferences", middleware.RequireIdentity(), inboxWrite, inboxHandler.Proxy)
 	}
 
 	// Create HTTP server
@@ -65,6 +139,8 @@ func main() {
 		Addr:    ":" + cfg.Port,
 		Handler: router,
 	}
+	// Shutdown waits for active requests, so open streams must be told to finish
+	server.RegisterOnShutdown(inboxHandler.Shutdown)
 
 	// Start server in a goroutine
 	go func() {
@@ -90,5 +166,10 @@ func main() {
 		logger.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Hijacked WebSocket connections are not covered by Shutdown
+	if err := inboxHandler.Wait(ctx); err != nil {
+		logger.WithError(err).Warn("Inbox streams did not close in time")
+	}
+
 	logger.Info("Server exiting")
 }
\ No newline at end of file
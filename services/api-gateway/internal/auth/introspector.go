@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxCacheEntries bounds the cache so that a flood of made-up credentials cannot
+// exhaust memory
+const maxCacheEntries = 10000
+
+// Identity is the caller a credential resolves to. KeyID is set for API keys and
+// SessionID for access tokens.
+type Identity struct {
+	Active    bool     `json:"active"`
+	UserID    string   `json:"user_id"`
+	Role      string   `json:"role"`
+	Scopes    []string `json:"scopes"`
+	KeyID     string   `json:"key_id"`
+	SessionID string   `json:"session_id"`
+}
+
+// HasScope reports whether the identity was granted scope
+func (i *Identity) HasScope(scope string) bool {
+	for _, s := range i.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type cacheEntry struct {
+	identity *Identity
+	expires  time.Time
+}
+
+// Introspector resolves credentials through the user service and caches the answers,
+// including rejections, for a short time. Revoking an API key or ending a session
+// therefore takes effect at the gateway within the cache TTL.
+type Introspector struct {
+	url          string
+	serviceToken string
+	client       *http.Client
+	ttl          time.Duration
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]cacheEntry
+}
+
+// NewIntrospector creates a new Introspector for the user service at userServiceURL,
+// which only answers requests carrying serviceToken
+func NewIntrospector(userServiceURL, serviceToken string, ttl time.Duration) *Introspector {
+	return &Introspector{
+		url:          userServiceURL + "/auth/introspect",
+		serviceToken: serviceToken,
+		client:       &http.Client{Timeout: 5 * time.Second},
+		ttl:          ttl,
+		cache:        make(map[[sha256.Size]byte]cacheEntry),
+	}
+}
+
+// Introspect resolves a credential. Credentials that are unknown, expired or revoked
+// resolve to an inactive identity.
+func (i *Introspector) Introspect(ctx context.Context, token string) (*Identity, error) {
+	// Keys are hashed so that the cache does not hold credentials in memory
+	key := sha256.Sum256([]byte(token))
+	now := time.Now()
+
+	i.mu.Lock()
+	entry, ok := i.cache[key]
+	i.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.identity, nil
+	}
+
+	identity, err := i.fetch(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	i.mu.Lock()
+	if len(i.cache) >= maxCacheEntries {
+		i.evict(now)
+	}
+	i.cache[key] = cacheEntry{identity: identity, expires: now.Add(i.ttl)}
+	i.mu.Unlock()
+
+	return identity, nil
+}
+
+// fetch asks the user service about a credential
+func (i *Introspector) fetch(ctx context.Context, token string) (*Identity, error) {
+	body, err := json.Marshal(map[string]string{"token": token})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Service-Token", i.serviceToken)
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user service returned status %d", resp.StatusCode)
+	}
+
+	var identity Identity
+	if err := json.NewDecoder(resp.Body).Decode(&identity); err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// evict drops expired entries, or everything if none has expired. The caller must hold mu.
+func (i *Introspector) evict(now time.Time) {
+	for key, entry := range i.cache {
+		if !now.Before(entry.expires) {
+			delete(i.cache, key)
+		}
+	}
+	if len(i.cache) >= maxCacheEntries {
+		i.cache = make(map[[sha256.Size]byte]cacheEntry)
+	}
+}
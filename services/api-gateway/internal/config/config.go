@@ -3,14 +3,26 @@ package config
 import (
 	"errors"
 	"os"
+	"strings"
+	"time"
 )
 
 // Config holds the application configuration
 type Config struct {
-	Port            string
-	UserServiceURL  string
-	ProductServiceURL string
-	Environment     string
+	Port                   string
+	UserServiceURL         string
+	ProductServiceURL      string
+	NotificationServiceURL string
+	Environment            string
+	// AuthCacheTTL bounds how long resolved credentials are reused, and therefore how long
+	// a revoked credential keeps working at the gateway
+	AuthCacheTTL time.Duration
+	// ServiceToken is sent in the X-Service-Token header so that services only trust the
+	// identity headers on requests coming through the gateway
+	ServiceToken string
+	// TrustedProxies are the addresses or CIDRs of load balancers in front of the gateway
+	// whose X-Forwarded-For header is believed. By default none are.
+	TrustedProxies []string
 }
 
 // Load loads the configuration from environment variables
@@ -30,15 +42,45 @@ func Load() (*Config, error) {
 		return nil, errors.New("PRODUCT_SERVICE_URL environment variable is required")
 	}
 
+	notificationServiceURL := os.Getenv("NOTIFICATION_SERVICE_URL")
+	if notificationServiceURL == "" {
+		return nil, errors.New("NOTIFICATION_SERVICE_URL environment variable is required")
+	}
+
+	serviceToken := os.Getenv("SERVICE_TOKEN")
+	if serviceToken == "" {
+		return nil, errors.New("SERVICE_TOKEN environment variable is required")
+	}
+
+	var trustedProxies []string
+	for _, v := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			trustedProxies = append(trustedProxies, v)
+		}
+	}
+
 	environment := os.Getenv("ENVIRONMENT")
 	if environment == "" {
 		environment = "development" // Default environment
 	}
 
+	authCacheTTL := 30 * time.Second
+	if v := os.Getenv("AUTH_CACHE_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, errors.New("AUTH_CACHE_TTL must be a positive duration such as 30s")
+		}
+		authCacheTTL = d
+	}
+
 	return &Config{
-		Port:            port,
-		UserServiceURL:  userServiceURL,
-		ProductServiceURL: productServiceURL,
-		Environment:     environment,
+		Port:                   port,
+		UserServiceURL:         userServiceURL,
+		ProductServiceURL:      productServiceURL,
+		NotificationServiceURL: notificationServiceURL,
+		Environment:            environment,
+		AuthCacheTTL:           authCacheTTL,
+		ServiceToken:           serviceToken,
+		TrustedProxies:         trustedProxies,
 	}, nil
 }
\ No newline at end of file
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// closeTimeout bounds writing a close frame to a WebSocket peer
+const closeTimeout = 5 * time.Second
+
+// upgrader accepts WebSocket connections from any origin, matching the notification service
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// InboxHandler proxies in-app inbox requests, including long-lived SSE and WebSocket
+// streams, to the notification service
+type InboxHandler struct {
+	notificationServiceURL string
+	serviceToken           string
+	proxy                  *httputil.ReverseProxy
+	logger                 *logrus.Logger
+
+	// ctx is cancelled when the gateway shuts down so that open streams finish
+	ctx    context.Context
+	cancel context.CancelFunc
+	// conns tracks hijacked WebSocket connections, which http.Server.Shutdown does not wait for
+	conns sync.WaitGroup
+}
+
+// NewInboxHandler creates a new InboxHandler
+func NewInboxHandler(notificationServiceURL, serviceToken string, logger *logrus.Logger) (*InboxHandler, error) {
+	target, err := url.Parse(notificationServiceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	// Flush every write so that server-sent events reach the client immediately
+	proxy.FlushInterval = -1
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		if r.Context().Err() == nil {
+			logger.WithError(err).Error("Failed to proxy request to notification service")
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &InboxHandler{
+		notificationServiceURL: notificationServiceURL,
+		serviceToken:           serviceToken,
+		proxy:                  proxy,
+		logger:                 logger,
+		ctx:                    ctx,
+		cancel:                 cancel,
+	}, nil
+}
+
+// Proxy forwards an inbox or preference request to the notification service without
+// buffering the response. Streams are cut off when the gateway shuts down.
+func (h *InboxHandler) Proxy(c *gin.Context) {
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-h.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	req := c.Request.Clone(ctx)
+	req.URL.Path = strings.TrimPrefix(req.URL.Path, "/api")
+	req.URL.RawPath = ""
+	req.Header.Set(serviceTokenHeader, h.serviceToken)
+
+	h.proxy.ServeHTTP(c.Writer, req)
+}
+
+// WebSocket relays an inbox WebSocket between the client and the notification service.
+// When either side or the gateway goes away, the other side receives a close frame.
+func (h *InboxHandler) WebSocket(c *gin.Context) {
+	if h.ctx.Err() != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Server is shutting down",
+		})
+		return
+	}
+	h.conns.Add(1)
+	defer h.conns.Done()
+
+	// Authenticate has replaced any X-User-ID sent by the client
+	header := http.Header{}
+	header.Set("X-User-ID", c.GetHeader("X-User-ID"))
+	header.Set(serviceTokenHeader, h.serviceToken)
+
+	backendURL := strings.Replace(h.notificationServiceURL, "http", "ws", 1) + "/inbox/ws"
+	backend, resp, err := websocket.DefaultDialer.DialContext(c.Request.Context(), backendURL, header)
+	if err != nil {
+		status := http.StatusBadGateway
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		h.logger.WithError(err).Error("Failed to connect to notification service stream")
+		c.JSON(status, gin.H{
+			"error": "Notification service error",
+		})
+		return
+	}
+	defer backend.Close()
+
+	client, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already replied to the client
+		h.logger.WithError(err).Warn("Failed to upgrade inbox stream")
+		return
+	}
+	defer client.Close()
+
+	errc := make(chan error, 2)
+	go relay(client, backend, errc)
+	go relay(backend, client, errc)
+
+	var closing []byte
+	select {
+	case err := <-errc:
+		// Pass the close code of whichever side ended the stream on to the other
+		closing = websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+		if ce, ok := err.(*websocket.CloseError); ok && ce.Code != websocket.CloseNoStatusReceived {
+			closing = websocket.FormatCloseMessage(ce.Code, ce.Text)
+		}
+	case <-h.ctx.Done():
+		closing = websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	}
+
+	deadline := time.Now().Add(closeTimeout)
+	client.WriteControl(websocket.CloseMessage, closing, deadline)
+	backend.WriteControl(websocket.CloseMessage, closing, deadline)
+}
+
+// Shutdown ends open streams and rejects new WebSocket connections. Register it with
+// http.Server.RegisterOnShutdown.
+func (h *InboxHandler) Shutdown() {
+	h.cancel()
+}
+
+// Wait blocks until every WebSocket relay has finished or ctx is done
+func (h *InboxHandler) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.conns.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// relay copies messages from src to dst until either connection fails
+func relay(dst, src *websocket.Conn, errc chan<- error) {
+	for {
+		messageType, data, err := src.ReadMessage()
+		if err != nil {
+			errc <- err
+			return
+		}
+		if err := dst.WriteMessage(messageType, data); err != nil {
+			errc <- err
+			return
+		}
+	}
+}
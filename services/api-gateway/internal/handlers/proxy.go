@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// serviceTokenHeader carries the token that makes services trust the identity headers
+const serviceTokenHeader = "X-Service-Token"
+
+// NewProxy returns a handler that forwards requests to the service at serviceURL with
+// the /api prefix removed and serviceToken attached, passing the response through
+// unchanged
+func NewProxy(serviceURL, serviceToken string, logger *logrus.Logger) (gin.HandlerFunc, error) {
+	target, err := url.Parse(serviceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		logger.WithError(err).WithField("target", serviceURL).Error("Failed to proxy request")
+		w.WriteHeader(http.StatusBadGateway)
+	}
+
+	return func(c *gin.Context) {
+		req := c.Request.Clone(c.Request.Context())
+		req.URL.Path = strings.TrimPrefix(req.URL.Path, "/api")
+		req.URL.RawPath = ""
+		req.Header.Set(serviceTokenHeader, serviceToken)
+		proxy.ServeHTTP(c.Writer, req)
+	}, nil
+}
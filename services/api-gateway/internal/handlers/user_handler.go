@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -11,13 +12,15 @@ import (
 // UserHandler handles user service-related requests
 type UserHandler struct {
 	userServiceURL string
+	serviceToken   string
 	logger         *logrus.Logger
 }
 
 // NewUserHandler creates a new UserHandler
-func NewUserHandler(userServiceURL string, logger *logrus.Logger) *UserHandler {
+func NewUserHandler(userServiceURL, serviceToken string, logger *logrus.Logger) *UserHandler {
 	return &UserHandler{
 		userServiceURL: userServiceURL,
+		serviceToken:   serviceToken,
 		logger:         logger,
 	}
 }
@@ -57,7 +60,27 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 // GetUser gets a user by ID
 func (h *UserHandler) GetUser(c *gin.Context) {
 	id := c.Param("id")
-	resp, err := http.Get(h.userServiceURL + "/users/" + id)
+	target := h.userServiceURL + "/users/" + url.PathEscape(id)
+	if asOf := c.Query("as_of"); asOf != "" {
+		target += "?as_of=" + url.QueryEscape(asOf)
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, target, nil)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create request to user service")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get user",
+		})
+		return
+	}
+	// Past versions are only shown to the user and admins
+	for _, header := range []string{"Authorization", "X-User-ID", "X-User-Role", "X-Request-ID"} {
+		if v := c.GetHeader(header); v != "" {
+			req.Header.Set(header, v)
+		}
+	}
+	req.Header.Set(serviceTokenHeader, h.serviceToken)
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get user from user service")
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -100,6 +123,13 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 	}
 	
 	req.Header.Set("Content-Type", "application/json")
+	// Identify the caller and request for the user service's audit log
+	for _, header := range []string{"Authorization", "X-User-ID", "X-Request-ID"} {
+		if v := c.GetHeader(header); v != "" {
+			req.Header.Set(header, v)
+		}
+	}
+	req.Header.Set(serviceTokenHeader, h.serviceToken)
 	
 	resp, err := client.Do(req)
 	if err != nil {
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/api-gateway/internal/auth"
+)
+
+// apiKeyPrefix starts every API key issued by the user service
+const apiKeyPrefix = "gmk_"
+
+// identityKey is the context key of the identity resolved by Authenticate
+const identityKey = "identity"
+
+// Identity headers forwarded to the services. Values sent by clients are always removed.
+var identityHeaders = []string{"X-User-ID", "X-User-Role", "X-User-Scopes"}
+
+// Authenticate returns a middleware that resolves the API key or access token in the
+// Authorization header and forwards the identity behind it in the X-User-ID and
+// X-User-Role headers, plus X-User-Scopes for API keys. API keys themselves are not
+// forwarded; access tokens are, for the services that check them. Requests without
+// credentials pass through unchanged.
+func Authenticate(introspector *auth.Introspector, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, header := range identityHeaders {
+			c.Request.Header.Del(header)
+		}
+
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.Next()
+			return
+		}
+
+		identity, err := introspector.Introspect(c.Request.Context(), token)
+		if err != nil {
+			logger.WithError(err).Error("Failed to introspect credential")
+			c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{
+				"error": "Failed to authenticate",
+			})
+			return
+		}
+		if !identity.Active {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid or revoked credentials",
+			})
+			return
+		}
+
+		c.Request.Header.Set("X-User-ID", identity.UserID)
+		c.Request.Header.Set("X-User-Role", identity.Role)
+		if strings.HasPrefix(token, apiKeyPrefix) {
+			c.Request.Header.Del("Authorization")
+			c.Request.Header.Set("X-User-Scopes", strings.Join(identity.Scopes, " "))
+		}
+		c.Set(identityKey, identity)
+		c.Next()
+	}
+}
+
+// Anonymous returns a middleware for routes that exchange credentials, such as login and
+// refresh, where an expired access token must not get in the way. It only removes the
+// identity headers sent by clients.
+func Anonymous() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, header := range identityHeaders {
+			c.Request.Header.Del(header)
+		}
+		c.Next()
+	}
+}
+
+// RequireIdentity returns a middleware that rejects requests Authenticate did not resolve
+// an identity for, on routes that act for the caller
+func RequireIdentity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(identityKey); !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Authentication required",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireActionIdentity is RequireIdentity for the given custom methods of a route that
+// serves several through an :action parameter
+func RequireActionIdentity(actions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, action := range actions {
+			if c.Param("action") == action {
+				RequireIdentity()(c)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireScope returns a middleware that rejects requests authenticated with an API key
+// that was not granted scope. Other requests are left to the services to authorize.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if v, ok := c.Get(identityKey); ok {
+			identity := v.(*auth.Identity)
+			if identity.KeyID != "" && !identity.HasScope(scope) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": "API key lacks scope " + scope,
+				})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireActionScope is RequireScope for a route that serves several custom methods
+// through an :action parameter, such as /users:batchGet, each needing its own scope
+func RequireActionScope(scopes map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopes[c.Param("action")]
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"error": "Unknown method",
+			})
+			return
+		}
+		RequireScope(scope)(c)
+	}
+}
@@ -34,6 +34,9 @@ func Logger(logger *logrus.Logger) gin.HandlerFunc {
 		if raw != "" {
 			fields["query"] = raw
 		}
+		if requestID := c.GetString(RequestIDKey); requestID != "" {
+			fields["request_id"] = requestID
+		}
 
 		// Log request
 		msg := "Request processed"
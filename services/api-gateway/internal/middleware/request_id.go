@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the ID that ties a request to its logs and audit entries
+// across services
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the context key set by RequestID
+const RequestIDKey = "request_id"
+
+// maxRequestIDLength bounds the request IDs accepted from clients
+const maxRequestIDLength = 64
+
+// RequestID returns a middleware that keeps the client's X-Request-ID, or assigns a new
+// one, forwards it to the services and echoes it in the response
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+
+		c.Request.Header.Set(RequestIDHeader, id)
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// newRequestID returns a random 128-bit ID in hex
+func newRequestID() string {
+	b := make([]byte, 16)
+	// crypto/rand.Read only fails when the system source is unavailable
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata" // Quiet hours need time zones the runtime image may not ship
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/notification-service/internal/channel"
+	"github.com/yourusername/go-microservices/notification-service/internal/config"
+	"github.com/yourusername/go-microservices/notification-service/internal/database"
+	"github.com/yourusername/go-microservices/notification-service/internal/handlers"
+	"github.com/yourusername/go-microservices/notification-service/internal/inbox"
+	"github.com/yourusername/go-microservices/notification-service/internal/middleware"
+	"github.com/yourusername/go-microservices/notification-service/internal/repository"
+	"github.com/yourusername/go-microservices/notification-service/internal/service"
+	"github.com/yourusername/go-microservices/notification-service/internal/worker"
+)
+
+func main() {
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.SetOutput(os.Stdout)
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Initialize database
+	db, err := database.Connect(cfg.DatabaseURL())
+	if err != nil {
+		logger.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Run migrations
+	if err := database.MigrateUp(cfg.DatabaseURL()); err != nil {
+		logger.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	// Initialize repositories
+	notificationRepo := repository.NewNotificationRepository(db)
+	templateRepo := repository.NewTemplateRepository(db)
+	webhookRepo := repository.NewWebhookSubscriberRepository(db)
+	preferenceRepo := repository.NewPreferenceRepository(db)
+	inboxRepo := repository.NewInboxRepository(db)
+	userDataRepo := repository.NewUserDataRepository(db)
+
+	// Initialize services
+	templateService := service.NewTemplateService(templateRepo, cfg.DefaultLocale)
+	webhookService := service.NewWebhookService(webhookRepo)
+	digestService := service.NewDigestService(notificationRepo, templateService, cfg.DigestTemplate)
+	preferenceService := service.NewPreferenceService(preferenceRepo, digestService)
+	inboxService := service.NewInboxService(inboxRepo)
+
+	// Initialize delivery channels
+	channels := channel.NewRegistry(
+		channel.NewLogChannel(logger),
+		channel.NewWebhookChannel(cfg.WebhookTimeout, webhookService),
+		channel.NewInAppChannel(inboxService),
+	)
+	if cfg.SMTPHost != "" {
+		channels.Register(channel.NewEmailChannel(cfg.SMTPAddr(), cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom))
+	} else {
+		logger.Warn("SMTP_HOST is not set, email channel is disabled")
+	}
+
+	notificationService := service.NewNotificationService(notificationRepo, templateService, channels)
+	userDataService := service.NewUserDataService(userDataRepo)
+	eventService := service.NewEventService(notificationService, userDataService, cfg.EventChannel)
+
+	// Start delivery workers
+	workers := worker.NewPool(notificationRepo, preferenceService, channels, cfg.RetryPolicies, cfg.WorkerCount, cfg.WorkerPollInterval, logger)
+	workers.Start()
+	digests := worker.NewDigestScheduler(digestService, cfg.DigestInterval, logger)
+	digests.Start()
+
+	// Relay new inbox messages from every replica to the live streams connected here
+	hub := inbox.NewHub()
+	listener, err := inbox.Listen(cfg.DatabaseURL(), inboxRepo, hub, logger)
+	if err != nil {
+		logger.Fatalf("Failed to listen for inbox messages: %v", err)
+	}
+
+	// Initialize router
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(middleware.Logger(logger))
+	router.Use(middleware.PrometheusMetrics())
+
+	// Health check endpoint
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status": "UP",
+			"name":   "notification-service",
+		})
+	})
+
+	// Prometheus metrics endpoint
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// API routes. Only other services may send notifications, read them or manage templates.
+	requireServiceToken := middleware.RequireServiceToken(cfg.ServiceToken)
+
+	notificationHandler := handlers.NewNotificationHandler(notificationService, logger)
+	notificationGroup := router.Group("/notifications", requireServiceToken)
+	{
+		notificationGroup.POST("", notificationHandler.CreateNotification)
+		notificationGroup.GET("/:id", notificationHandler.GetNotification)
+		notificationGroup.DELETE("/:id", notificationHandler.CancelNotification)
+	}
+
+	// User events can erase data and carry account links, so only user-service may post them
+	eventHandler := handlers.NewEventHandler(eventService, logger)
+	router.POST("/events/users", requireServiceToken, eventHandler.HandleUserEvent)
+
+	// Preference routes act for the user in X-User-ID, like the inbox routes
+	preferenceHandler := handlers.NewPreferenceHandler(preferenceService, logger)
+	router.GET("/users/:id/preferences", requireServiceToken, preferenceHandler.GetPreference)
+	router.PUT("/users/:id/preferences", requireServiceToken, preferenceHandler.UpdatePreference)
+
+	// Used by the user service to assemble data exports; not exposed through the gateway
+	userDataHandler := handlers.NewUserDataHandler(userDataService, logger)
+	router.GET("/users/:id/data", requireServiceToken, userDataHandler.ExportUserData)
+
+	templateHandler := handlers.NewTemplateHandler(templateService, logger)
+	templateGroup := router.Group("/templates", requireServiceToken)
+	{
+		templateGroup.GET("", templateHandler.GetTemplates)
+		templateGroup.GET("/:name", templateHandler.GetTemplate)
+		templateGroup.POST("", templateHandler.CreateTemplate)
+		templateGroup.POST("/:name/preview", templateHandler.PreviewTemplate)
+	}
+
+	// Inbox routes act for the user in X-User-ID, which only the gateway may set
+	inboxHandler := handlers.NewInboxHandler(inboxService, hub, logger)
+	inboxGroup := router.Group("/inbox", requireServiceToken)
+	{
+		inboxGroup.GET("", inboxHandler.GetInbox)
+		inboxGroup.GET("/unread-count", inboxHandler.GetUnreadCount)
+		inboxGroup.POST("/read-all", inboxHandler.MarkAllRead)
+		inboxGroup.POST("/:id/read", inboxHandler.MarkRead)
+		inboxGroup.GET("/stream", inboxHandler.Stream)
+		inboxGroup.GET("/ws", inboxHandler.WebSocket)
+	}
+
+	adminHandler := handlers.NewAdminHandler(notificationService, logger)
+	// Admin routes hand out webhook signing secrets, so they are only open to other services
+	adminGroup := router.Group("/admin", requireServiceToken)
+	{
+		adminGroup.GET("/dead-letters", adminHandler.GetDeadLetters)
+		adminGroup.POST("/dead-letters/:id/requeue", adminHandler.RequeueDeadLetter)
+
+		webhookHandler := handlers.NewWebhookHandler(webhookService, logger)
+		adminGroup.GET("/webhook-subscribers", webhookHandler.GetSubscribers)
+		adminGroup.POST("/webhook-subscribers", webhookHandler.CreateSubscriber)
+		adminGroup.POST("/webhook-subscribers/:id/rotate", webhookHandler.RotateSecret)
+		adminGroup.DELETE("/webhook-subscribers/:id", webhookHandler.DeleteSubscriber)
+	}
+
+	// Create HTTP server
+	server := &http.Server{
+		Addr:    ":" + cfg.Port,
+		Handler: router,
+	}
+	// Shutdown waits for active requests, so open inbox streams must be told to finish
+	server.RegisterOnShutdown(hub.Close)
+
+	// Start server in a goroutine
+	go func() {
+		logger.Infof("Starting Notification Service on port %s", cfg.Port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Wait for interrupt signal to gracefully shut down the server
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logger.Info("Shutting down server...")
+
+	// Create context with timeout for shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Shutdown the server
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Fatalf("Server forced to shutdown: %v", err)
+	}
+
+	// Hijacked WebSocket connections are not covered by Shutdown
+	if err := hub.Wait(ctx); err != nil {
+		logger.WithError(err).Warn("Inbox streams did not close in time")
+	}
+
+	// Let in-flight deliveries finish; unfinished claims are picked up again after restart
+	workers.Stop()
+	digests.Stop()
+	listener.Close()
+
+	logger.Info("Server exiting")
+}
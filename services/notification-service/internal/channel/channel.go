@@ -0,0 +1,76 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Message is a rendered notification ready for delivery
+type Message struct {
+	ID        string                 `json:"id"`
+	Recipient string                 `json:"recipient"`
+	Template  string                 `json:"template"`
+	Subject   string                 `json:"subject"`
+	Body      string                 `json:"body"`
+	HTMLBody  string                 `json:"html_body,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// Channel delivers messages to recipients over a single transport
+type Channel interface {
+	// Name returns the channel name used in notification requests
+	Name() string
+	// Send delivers the message to its recipient
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Registry holds the channels available for delivery
+type Registry struct {
+	channels map[string]Channel
+}
+
+// NewRegistry creates a new Registry with the given channels
+func NewRegistry(channels ...Channel) *Registry {
+	r := &Registry{
+		channels: make(map[string]Channel),
+	}
+	for _, ch := range channels {
+		r.Register(ch)
+	}
+	return r
+}
+
+// Register adds a channel to the registry, replacing any channel with the same name
+func (r *Registry) Register(ch Channel) {
+	r.channels[ch.Name()] = ch
+}
+
+// Get returns the channel with the given name
+func (r *Registry) Get(name string) (Channel, error) {
+	ch, ok := r.channels[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown channel: %s", name)
+	}
+	return ch, nil
+}
+
+// PermanentError marks a delivery failure that will not succeed on retry,
+// such as a webhook receiver rejecting the payload
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanent reports whether err is a delivery failure that should not be retried
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
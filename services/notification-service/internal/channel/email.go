@@ -0,0 +1,97 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// EmailChannel delivers messages over SMTP
+type EmailChannel struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewEmailChannel creates a new EmailChannel. Authentication is skipped when username is empty.
+func NewEmailChannel(addr, username, password, from string) *EmailChannel {
+	var auth smtp.Auth
+	if username != "" {
+		host := addr
+		if i := strings.LastIndex(addr, ":"); i >= 0 {
+			host = addr[:i]
+		}
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &EmailChannel{
+		addr: addr,
+		from: from,
+		auth: auth,
+	}
+}
+
+// Name returns the channel name
+func (c *EmailChannel) Name() string {
+	return "email"
+}
+
+// Send delivers the message as an email, including the HTML body when present
+func (c *EmailChannel) Send(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(c.addr, c.auth, c.from, []string{msg.Recipient}, c.buildMessage(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+func (c *EmailChannel) buildMessage(msg *Message) []byte {
+	var b bytes.Buffer
+	b.WriteString("From: " + c.from + "\r\n")
+	b.WriteString("To: " + msg.Recipient + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTMLBody == "" {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		b.WriteString("\r\n")
+		b.WriteString(crlf(msg.Body))
+		return b.Bytes()
+	}
+
+	// Send both bodies and let the client pick the richest one it supports
+	w := multipart.NewWriter(&b)
+	b.WriteString("Content-Type: multipart/alternative; boundary=" + w.Boundary() + "\r\n")
+	b.WriteString("\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=UTF-8", msg.Body},
+		{"text/html; charset=UTF-8", msg.HTMLBody},
+	} {
+		pw, err := w.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			continue
+		}
+		_, _ = pw.Write([]byte(crlf(part.body)))
+	}
+	_ = w.Close()
+
+	return b.Bytes()
+}
+
+func crlf(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+}
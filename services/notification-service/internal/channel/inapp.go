@@ -0,0 +1,35 @@
+package channel
+
+import (
+	"context"
+)
+
+// InboxWriter stores messages in users' in-app inboxes
+type InboxWriter interface {
+	Deliver(msg *Message) error
+}
+
+// InAppChannel delivers messages to the in-app inbox of the user named as recipient
+type InAppChannel struct {
+	inbox InboxWriter
+}
+
+// NewInAppChannel creates a new InAppChannel
+func NewInAppChannel(inbox InboxWriter) *InAppChannel {
+	return &InAppChannel{
+		inbox: inbox,
+	}
+}
+
+// Name returns the channel name
+func (c *InAppChannel) Name() string {
+	return "in_app"
+}
+
+// Send stores the message in the recipient's inbox
+func (c *InAppChannel) Send(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.inbox.Deliver(msg)
+}
@@ -0,0 +1,36 @@
+package channel
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogChannel writes messages to the service log instead of delivering them
+type LogChannel struct {
+	logger *logrus.Logger
+}
+
+// NewLogChannel creates a new LogChannel
+func NewLogChannel(logger *logrus.Logger) *LogChannel {
+	return &LogChannel{
+		logger: logger,
+	}
+}
+
+// Name returns the channel name
+func (c *LogChannel) Name() string {
+	return "log"
+}
+
+// Send logs the message
+func (c *LogChannel) Send(ctx context.Context, msg *Message) error {
+	c.logger.WithFields(logrus.Fields{
+		"notification_id": msg.ID,
+		"recipient":       msg.Recipient,
+		"template":        msg.Template,
+		"subject":         msg.Subject,
+		"body":            msg.Body,
+	}).Info("Notification delivered")
+	return nil
+}
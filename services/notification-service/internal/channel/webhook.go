@@ -0,0 +1,82 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/go-microservices/notification-service/pkg/webhook"
+)
+
+// ErrNoSubscriber is returned by a SecretSource when no subscriber is registered for a URL
+var ErrNoSubscriber = errors.New("no webhook subscriber registered for URL")
+
+// SecretSource looks up the signing secrets of the subscriber registered for a webhook URL
+type SecretSource interface {
+	WebhookSecrets(url string) ([]string, error)
+}
+
+// WebhookChannel delivers messages as signed JSON to the recipient URL. Only URLs with a
+// registered subscriber receive deliveries.
+type WebhookChannel struct {
+	client  *http.Client
+	secrets SecretSource
+}
+
+// NewWebhookChannel creates a new WebhookChannel
+func NewWebhookChannel(timeout time.Duration, secrets SecretSource) *WebhookChannel {
+	return &WebhookChannel{
+		client:  &http.Client{Timeout: timeout},
+		secrets: secrets,
+	}
+}
+
+// Name returns the channel name
+func (c *WebhookChannel) Name() string {
+	return "webhook"
+}
+
+// Send posts the message to the recipient URL, signed with the subscriber's active secrets
+func (c *WebhookChannel) Send(ctx context.Context, msg *Message) error {
+	secrets, err := c.secrets.WebhookSecrets(msg.Recipient)
+	if err != nil {
+		if errors.Is(err, ErrNoSubscriber) {
+			return &PermanentError{Err: err}
+		}
+		return fmt.Errorf("failed to load webhook secrets: %w", err)
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.Recipient, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Notification-ID", msg.ID)
+	webhook.SignRequest(req, secrets, body, time.Now())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := fmt.Errorf("webhook receiver returned status %d", resp.StatusCode)
+		// Client errors other than timeouts and rate limiting will not succeed on retry
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+			resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			return &PermanentError{Err: err}
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how often and how quickly failed deliveries on a channel are retried
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Config holds the application configuration
+type Config struct {
+	Port   string
+	DBHost string
+	DBPort string
+	DBUser string
+	DBPass string
+	DBName string
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	WebhookTimeout time.Duration
+	DefaultLocale  string
+
+	WorkerCount        int
+	WorkerPollInterval time.Duration
+	RetryPolicies      map[string]RetryPolicy
+
+	DigestTemplate string
+	DigestInterval time.Duration
+
+	EventChannel string
+
+	// ServiceToken is shared with the gateway and the user service, which send it in the
+	// X-Service-Token header on calls that act for a user
+	ServiceToken string
+}
+
+// Load loads the configuration from environment variables
+func Load() (*Config, error) {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8083" // Default port
+	}
+
+	dbHost := os.Getenv("DB_HOST")
+	if dbHost == "" {
+		return nil, errors.New("DB_HOST environment variable is required")
+	}
+
+	dbPort := os.Getenv("DB_PORT")
+	if dbPort == "" {
+		dbPort = "5432" // Default PostgreSQL port
+	}
+
+	dbUser := os.Getenv("DB_USER")
+	if dbUser == "" {
+		return nil, errors.New("DB_USER environment variable is required")
+	}
+
+	dbPass := os.Getenv("DB_PASSWORD")
+	if dbPass == "" {
+		return nil, errors.New("DB_PASSWORD environment variable is required")
+	}
+
+	dbName := os.Getenv("DB_NAME")
+	if dbName == "" {
+		return nil, errors.New("DB_NAME environment variable is required")
+	}
+
+	serviceToken := os.Getenv("SERVICE_TOKEN")
+	if serviceToken == "" {
+		return nil, errors.New("SERVICE_TOKEN environment variable is required")
+	}
+
+	smtpPort := os.Getenv("SMTP_PORT")
+	if smtpPort == "" {
+		smtpPort = "25" // Default SMTP port
+	}
+
+	smtpFrom := os.Getenv("SMTP_FROM")
+	if smtpFrom == "" {
+		smtpFrom = "no-reply@localhost"
+	}
+
+	webhookTimeout := 10 * time.Second
+	if v := os.Getenv("WEBHOOK_TIMEOUT_SECONDS"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds <= 0 {
+			return nil, errors.New("WEBHOOK_TIMEOUT_SECONDS must be a positive integer")
+		}
+		webhookTimeout = time.Duration(seconds) * time.Second
+	}
+
+	defaultLocale := os.Getenv("DEFAULT_LOCALE")
+	if defaultLocale == "" {
+		defaultLocale = "en" // Last entry of every template locale fallback chain
+	}
+
+	workerCount := 4
+	if v := os.Getenv("WORKER_COUNT"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, errors.New("WORKER_COUNT must be a positive integer")
+		}
+		workerCount = n
+	}
+
+	workerPollInterval, err := durationEnv("WORKER_POLL_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	retryPolicies, err := loadRetryPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	digestTemplate := os.Getenv("DIGEST_TEMPLATE")
+	if digestTemplate == "" {
+		digestTemplate = "digest"
+	}
+
+	digestInterval, err := durationEnv("DIGEST_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	eventChannel := os.Getenv("EVENT_CHANNEL")
+	if eventChannel == "" {
+		eventChannel = "email" // Channel for welcome and email-changed messages
+	}
+
+	return &Config{
+		Port:   port,
+		DBHost: dbHost,
+		DBPort: dbPort,
+		DBUser: dbUser,
+		DBPass: dbPass,
+		DBName: dbName,
+
+		// SMTP is optional; the email channel is only registered when a host is set
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     smtpPort,
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:     smtpFrom,
+
+		WebhookTimeout: webhookTimeout,
+		DefaultLocale:  defaultLocale,
+
+		WorkerCount:        workerCount,
+		WorkerPollInterval: workerPollInterval,
+		RetryPolicies:      retryPolicies,
+
+		DigestTemplate: digestTemplate,
+		DigestInterval: digestInterval,
+
+		EventChannel: eventChannel,
+
+		ServiceToken: serviceToken,
+	}, nil
+}
+
+// defaultRetryPolicies are used for channels without RETRY_<CHANNEL>_* overrides
+var defaultRetryPolicies = map[string]RetryPolicy{
+	"email":   {MaxAttempts: 5, BaseDelay: 30 * time.Second, MaxDelay: time.Hour},
+	"webhook": {MaxAttempts: 8, BaseDelay: 10 * time.Second, MaxDelay: 30 * time.Minute},
+	"log":     {MaxAttempts: 1, BaseDelay: time.Second, MaxDelay: time.Second},
+	"in_app":  {MaxAttempts: 5, BaseDelay: 5 * time.Second, MaxDelay: time.Minute},
+}
+
+// loadRetryPolicies applies RETRY_<CHANNEL>_MAX_ATTEMPTS, RETRY_<CHANNEL>_BASE_DELAY and
+// RETRY_<CHANNEL>_MAX_DELAY overrides to the default retry policies
+func loadRetryPolicies() (map[string]RetryPolicy, error) {
+	policies := make(map[string]RetryPolicy, len(defaultRetryPolicies))
+	for name, policy := range defaultRetryPolicies {
+		prefix := "RETRY_" + strings.ToUpper(name) + "_"
+
+		if v := os.Getenv(prefix + "MAX_ATTEMPTS"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("%sMAX_ATTEMPTS must be a positive integer", prefix)
+			}
+			policy.MaxAttempts = n
+		}
+
+		var err error
+		if policy.BaseDelay, err = durationEnv(prefix+"BASE_DELAY", policy.BaseDelay); err != nil {
+			return nil, err
+		}
+		if policy.MaxDelay, err = durationEnv(prefix+"MAX_DELAY", policy.MaxDelay); err != nil {
+			return nil, err
+		}
+
+		policies[name] = policy
+	}
+	return policies, nil
+}
+
+// durationEnv parses a Go duration such as "30s" from an environment variable
+func durationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration", key)
+	}
+	return d, nil
+}
+
+// DatabaseURL returns the database connection URL
+func (c *Config) DatabaseURL() string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
+		c.DBUser, c.DBPass, c.DBHost, c.DBPort, c.DBName)
+}
+
+// SMTPAddr returns the SMTP server address
+func (c *Config) SMTPAddr() string {
+	return c.SMTPHost + ":" + c.SMTPPort
+}
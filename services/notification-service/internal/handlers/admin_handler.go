@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/notification-service/internal/service"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// AdminHandler handles operational requests for the delivery queue
+type AdminHandler struct {
+	service *service.NotificationService
+	logger  *logrus.Logger
+}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler(service *service.NotificationService, logger *logrus.Logger) *AdminHandler {
+	return &AdminHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetDeadLetters gets a page of dead-lettered notifications
+func (h *AdminHandler) GetDeadLetters(c *gin.Context) {
+	limit, offset, ok := pagination(c)
+	if !ok {
+		return
+	}
+
+	notifications, err := h.service.GetDeadLetters(limit, offset)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get dead letters")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get dead letters",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// RequeueDeadLetter puts a dead-lettered notification back on the queue
+func (h *AdminHandler) RequeueDeadLetter(c *gin.Context) {
+	id := c.Param("id")
+
+	notification, err := h.service.RequeueDeadLetter(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Dead letter not found",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrRedacted) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to requeue dead letter")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to requeue dead letter",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, notification)
+}
+
+// pagination reads the limit and offset query parameters, responding with 400 when they are invalid
+func pagination(c *gin.Context) (int, int, bool) {
+	limit := defaultPageSize
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid limit",
+			})
+			return 0, 0, false
+		}
+		limit = n
+	}
+
+	offset := 0
+	if v := c.Query("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid offset",
+			})
+			return 0, 0, false
+		}
+		offset = n
+	}
+
+	return limit, offset, true
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+	"github.com/yourusername/go-microservices/notification-service/internal/service"
+	"github.com/yourusername/go-microservices/notification-service/internal/templating"
+)
+
+// EventHandler handles events published by other services
+type EventHandler struct {
+	service *service.EventService
+	logger  *logrus.Logger
+}
+
+// NewEventHandler creates a new EventHandler
+func NewEventHandler(service *service.EventService, logger *logrus.Logger) *EventHandler {
+	return &EventHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleUserEvent queues the notifications for a user lifecycle event. Publishers retry
+// until they get a 2xx response, so only failures worth retrying return 5xx.
+func (h *EventHandler) HandleUserEvent(c *gin.Context) {
+	var event models.UserEvent
+	if err := c.ShouldBindJSON(&event); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	logger := h.logger.WithField("event_id", event.ID).WithField("event_type", event.Type)
+
+	notifications, err := h.service.HandleUserEvent(&event)
+	if err != nil {
+		if errors.Is(err, templating.ErrMissingVariables) || errors.Is(err, templating.ErrInvalidTemplate) {
+			logger.WithError(err).Error("User event cannot be rendered")
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		logger.WithError(err).Error("Failed to handle user event")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to handle user event",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, notifications)
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/notification-service/internal/inbox"
+	"github.com/yourusername/go-microservices/notification-service/internal/service"
+)
+
+const (
+	// heartbeatInterval keeps idle streams from being closed by proxies and load balancers
+	heartbeatInterval = 25 * time.Second
+	// writeTimeout bounds a single write to a WebSocket client
+	writeTimeout = 10 * time.Second
+)
+
+// upgrader accepts WebSocket connections from any origin. Streams are scoped by the
+// X-User-ID header the gateway sets, not by cookies, so cross-origin pages gain nothing.
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// InboxHandler handles in-app inbox requests
+type InboxHandler struct {
+	service *service.InboxService
+	hub     *inbox.Hub
+	logger  *logrus.Logger
+}
+
+// NewInboxHandler creates a new InboxHandler
+func NewInboxHandler(service *service.InboxService, hub *inbox.Hub, logger *logrus.Logger) *InboxHandler {
+	return &InboxHandler{
+		service: service,
+		hub:     hub,
+		logger:  logger,
+	}
+}
+
+// GetInbox gets a page of the caller's inbox messages, newest first
+func (h *InboxHandler) GetInbox(c *gin.Context) {
+	userID, ok := inboxUser(c)
+	if !ok {
+		return
+	}
+	limit, offset, ok := pagination(c)
+	if !ok {
+		return
+	}
+
+	messages, err := h.service.GetInbox(userID, c.Query("unread") == "true", limit, offset)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get inbox")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get inbox",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+// GetUnreadCount counts the caller's unread inbox messages
+func (h *InboxHandler) GetUnreadCount(c *gin.Context) {
+	userID, ok := inboxUser(c)
+	if !ok {
+		return
+	}
+
+	count, err := h.service.CountUnread(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to count unread messages")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to count unread messages",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"unread": count,
+	})
+}
+
+// MarkRead marks one of the caller's inbox messages as read
+func (h *InboxHandler) MarkRead(c *gin.Context) {
+	userID, ok := inboxUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.MarkRead(userID, c.Param("id")); err != nil {
+		if errors.Is(err, service.ErrInboxMessageNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Inbox message not found",
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to mark message as read")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to mark message as read",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MarkAllRead marks all of the caller's inbox messages as read
+func (h *InboxHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := inboxUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.MarkAllRead(userID); err != nil {
+		h.logger.WithError(err).Error("Failed to mark messages as read")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to mark messages as read",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Stream pushes the caller's new inbox messages as server-sent events. The stream ends
+// when the client disconnects or the server shuts down; clients reconnect and refetch.
+func (h *InboxHandler) Stream(c *gin.Context) {
+	userID, ok := inboxUser(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.hub.Subscribe(userID)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Server is shutting down",
+		})
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				return false
+			}
+			c.SSEvent("message", msg)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// WebSocket pushes the caller's new inbox messages over a WebSocket connection. On
+// shutdown the server sends a going-away close frame before disconnecting.
+func (h *InboxHandler) WebSocket(c *gin.Context) {
+	userID, ok := inboxUser(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.hub.Subscribe(userID)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Server is shutting down",
+		})
+		return
+	}
+	defer sub.Close()
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already replied to the client
+		h.logger.WithError(err).Warn("Failed to upgrade inbox stream")
+		return
+	}
+	defer conn.Close()
+
+	// The stream is push-only; reading is needed to process control frames and notice
+	// when the client goes away
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		conn.SetReadLimit(512)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				closing := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+				conn.WriteControl(websocket.CloseMessage, closing, time.Now().Add(writeTimeout))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
+
+// inboxUser returns the user the gateway authenticated the request for
+func inboxUser(c *gin.Context) (string, bool) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Missing user",
+		})
+		return "", false
+	}
+	return userID, true
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+	"github.com/yourusername/go-microservices/notification-service/internal/service"
+	"github.com/yourusername/go-microservices/notification-service/internal/templating"
+)
+
+// NotificationHandler handles notification-related requests
+type NotificationHandler struct {
+	service *service.NotificationService
+	logger  *logrus.Logger
+}
+
+// NewNotificationHandler creates a new NotificationHandler
+func NewNotificationHandler(service *service.NotificationService, logger *logrus.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetNotification gets a notification and its delivery status by ID
+func (h *NotificationHandler) GetNotification(c *gin.Context) {
+	id := c.Param("id")
+
+	notification, err := h.service.GetNotification(id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get notification")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Notification not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, notification)
+}
+
+// CancelNotification cancels a notification that has not been dispatched yet
+func (h *NotificationHandler) CancelNotification(c *gin.Context) {
+	id := c.Param("id")
+
+	notification, err := h.service.CancelNotification(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Notification not found",
+			})
+		case errors.Is(err, service.ErrNotCancellable):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			h.logger.WithError(err).Error("Failed to cancel notification")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to cancel notification",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, notification)
+}
+
+// CreateNotification queues a new notification for delivery
+func (h *NotificationHandler) CreateNotification(c *gin.Context) {
+	var req models.CreateNotificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	notification, created, err := h.service.CreateNotification(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnknownChannel):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Unknown channel",
+			})
+		case errors.Is(err, service.ErrTemplateNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Template not found",
+			})
+		case errors.Is(err, templating.ErrMissingVariables), errors.Is(err, templating.ErrInvalidTemplate):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			h.logger.WithError(err).Error("Failed to create notification")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to create notification",
+			})
+		}
+		return
+	}
+
+	if !created {
+		// Repeated dedup key: report the notification that was already queued
+		c.JSON(http.StatusOK, notification)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, notification)
+}
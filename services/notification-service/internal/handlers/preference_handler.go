@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+	"github.com/yourusername/go-microservices/notification-service/internal/service"
+)
+
+// PreferenceHandler handles notification preference requests
+type PreferenceHandler struct {
+	service *service.PreferenceService
+	logger  *logrus.Logger
+}
+
+// NewPreferenceHandler creates a new PreferenceHandler
+func NewPreferenceHandler(service *service.PreferenceService, logger *logrus.Logger) *PreferenceHandler {
+	return &PreferenceHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetPreference gets a user's notification preferences
+func (h *PreferenceHandler) GetPreference(c *gin.Context) {
+	userID, ok := preferenceUser(c)
+	if !ok {
+		return
+	}
+
+	preference, err := h.service.GetPreference(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get preferences")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get preferences",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
+
+// UpdatePreference replaces a user's notification preferences
+func (h *PreferenceHandler) UpdatePreference(c *gin.Context) {
+	userID, ok := preferenceUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdatePreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	preference, err := h.service.UpdatePreference(userID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPreference) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to update preferences")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update preferences",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
+
+// preferenceUser returns the user whose preferences are requested, who must be the user
+// the gateway authenticated the request for
+func preferenceUser(c *gin.Context) (string, bool) {
+	userID, ok := inboxUser(c)
+	if !ok {
+		return "", false
+	}
+	if userID != c.Param("id") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Cannot access another user's preferences",
+		})
+		return "", false
+	}
+	return userID, true
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+	"github.com/yourusername/go-microservices/notification-service/internal/service"
+	"github.com/yourusername/go-microservices/notification-service/internal/templating"
+)
+
+// TemplateHandler handles template-related requests
+type TemplateHandler struct {
+	service *service.TemplateService
+	logger  *logrus.Logger
+}
+
+// NewTemplateHandler creates a new TemplateHandler
+func NewTemplateHandler(service *service.TemplateService, logger *logrus.Logger) *TemplateHandler {
+	return &TemplateHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetTemplates gets every version of every template
+func (h *TemplateHandler) GetTemplates(c *gin.Context) {
+	templates, err := h.service.GetTemplates()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get templates")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get templates",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate gets every version of a template across all locales
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	name := c.Param("name")
+
+	templates, err := h.service.GetTemplateVersions(name)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get template")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Template not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// CreateTemplate publishes a new template version
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	var req models.CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	template, err := h.service.CreateTemplate(&req)
+	if err != nil {
+		if errors.Is(err, templating.ErrInvalidTemplate) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to create template")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create template",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// PreviewTemplate renders a template with sample data without sending it
+func (h *TemplateHandler) PreviewTemplate(c *gin.Context) {
+	name := c.Param("name")
+
+	var req models.PreviewTemplateRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.WithError(err).Error("Invalid request payload")
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request payload",
+			})
+			return
+		}
+	}
+
+	rendered, err := h.service.Preview(name, &req)
+	if err != nil {
+		respondRenderError(c, h.logger, err, "Failed to preview template")
+		return
+	}
+
+	c.JSON(http.StatusOK, rendered)
+}
+
+// respondRenderError maps template resolution and rendering errors to responses
+func respondRenderError(c *gin.Context, logger *logrus.Logger, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrTemplateNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Template not found",
+		})
+	case errors.Is(err, templating.ErrMissingVariables), errors.Is(err, templating.ErrInvalidTemplate):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		logger.WithError(err).Error(msg)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": msg,
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/notification-service/internal/service"
+)
+
+// UserDataHandler handles data export requests for users. Erasure is requested through
+// the user.erased event.
+type UserDataHandler struct {
+	service *service.UserDataService
+	logger  *logrus.Logger
+}
+
+// NewUserDataHandler creates a new UserDataHandler
+func NewUserDataHandler(service *service.UserDataService, logger *logrus.Logger) *UserDataHandler {
+	return &UserDataHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ExportUserData gets a user's notifications, inbox messages and preferences
+func (h *UserDataHandler) ExportUserData(c *gin.Context) {
+	data, err := h.service.Export(c.Param("id"))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to export user data")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to export user data",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+	"github.com/yourusername/go-microservices/notification-service/internal/service"
+)
+
+// WebhookHandler handles webhook subscriber requests
+type WebhookHandler struct {
+	service *service.WebhookService
+	logger  *logrus.Logger
+}
+
+// NewWebhookHandler creates a new WebhookHandler
+func NewWebhookHandler(service *service.WebhookService, logger *logrus.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetSubscribers gets all webhook subscribers
+func (h *WebhookHandler) GetSubscribers(c *gin.Context) {
+	subscribers, err := h.service.GetSubscribers()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get webhook subscribers")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get webhook subscribers",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscribers)
+}
+
+// CreateSubscriber registers a webhook URL. The response carries the signing secret,
+// which is not shown again.
+func (h *WebhookHandler) CreateSubscriber(c *gin.Context) {
+	var req models.CreateWebhookSubscriberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	subscriber, err := h.service.CreateSubscriber(&req)
+	if err != nil {
+		if errors.Is(err, service.ErrDuplicateSubscriber) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Webhook URL already registered",
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to create webhook subscriber")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create webhook subscriber",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscriber)
+}
+
+// RotateSecret issues a new signing secret while keeping the previous one valid for a grace period
+func (h *WebhookHandler) RotateSecret(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.RotateWebhookSecretRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.WithError(err).Error("Invalid request payload")
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request payload",
+			})
+			return
+		}
+	}
+
+	subscriber, err := h.service.RotateSecret(id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSubscriberNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Webhook subscriber not found",
+			})
+		case errors.Is(err, service.ErrInvalidGracePeriod):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid grace period",
+			})
+		default:
+			h.logger.WithError(err).Error("Failed to rotate webhook secret")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to rotate webhook secret",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriber)
+}
+
+// DeleteSubscriber removes a webhook subscriber
+func (h *WebhookHandler) DeleteSubscriber(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.service.DeleteSubscriber(id); err != nil {
+		h.logger.WithError(err).Error("Failed to delete webhook subscriber")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete webhook subscriber",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,130 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+)
+
+// subscriptionBuffer is how many messages a slow subscriber may fall behind before
+// new messages are dropped for it
+const subscriptionBuffer = 16
+
+// ErrHubClosed is returned when subscribing to a hub that is shutting down
+var ErrHubClosed = errors.New("inbox hub closed")
+
+// Hub fans inbox messages out to the live streams connected to this replica
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[string]map[*Subscription]struct{}
+	closed bool
+	active sync.WaitGroup
+}
+
+// NewHub creates a new Hub
+func NewHub() *Hub {
+	return &Hub{
+		subs: make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives a user's new inbox messages until it is closed. C is closed when
+// the subscription ends, either through Close or because the hub shut down.
+type Subscription struct {
+	C <-chan *models.InboxMessage
+
+	ch      chan *models.InboxMessage
+	hub     *Hub
+	userID  string
+	once    sync.Once
+	release sync.Once
+}
+
+// Subscribe registers a live stream for a user's messages
+func (h *Hub) Subscribe(userID string) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, ErrHubClosed
+	}
+
+	ch := make(chan *models.InboxMessage, subscriptionBuffer)
+	sub := &Subscription{C: ch, ch: ch, hub: h, userID: userID}
+
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+	h.active.Add(1)
+
+	return sub, nil
+}
+
+// Close ends the subscription and releases it from the hub's Wait. Streams call it once
+// they have finished writing. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	s.closeLocked()
+	s.hub.mu.Unlock()
+
+	s.release.Do(s.hub.active.Done)
+}
+
+func (s *Subscription) closeLocked() {
+	s.once.Do(func() {
+		if subs := s.hub.subs[s.userID]; subs != nil {
+			delete(subs, s)
+			if len(subs) == 0 {
+				delete(s.hub.subs, s.userID)
+			}
+		}
+		close(s.ch)
+	})
+}
+
+// Publish delivers a message to the recipient's live streams. Streams that are too far
+// behind miss the message; it is still in the inbox on their next fetch.
+func (h *Hub) Publish(msg *models.InboxMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs[msg.UserID] {
+		select {
+		case sub.ch <- msg:
+		default:
+		}
+	}
+}
+
+// Close ends every subscription so that connected streams finish, and rejects new ones.
+// Use Wait to block until the streams have released their subscriptions.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, subs := range h.subs {
+		for sub := range subs {
+			sub.closeLocked()
+		}
+	}
+}
+
+// Wait blocks until every subscription has been released or ctx is done
+func (h *Hub) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
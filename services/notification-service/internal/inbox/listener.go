@@ -0,0 +1,81 @@
+package inbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/notification-service/internal/repository"
+)
+
+// notifyChannel is the Postgres channel the inbox_messages insert trigger notifies
+const notifyChannel = "inbox_messages"
+
+// Listener relays inbox messages inserted by any replica to this replica's hub
+type Listener struct {
+	listener *pq.Listener
+	repo     *repository.InboxRepository
+	hub      *Hub
+	logger   *logrus.Logger
+	done     chan struct{}
+}
+
+// Listen starts relaying new inbox messages to the hub
+func Listen(dsn string, repo *repository.InboxRepository, hub *Hub, logger *logrus.Logger) (*Listener, error) {
+	pl := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.WithError(err).Warn("Inbox listener connection event")
+		}
+	})
+	if err := pl.Listen(notifyChannel); err != nil {
+		pl.Close()
+		return nil, err
+	}
+
+	l := &Listener{
+		listener: pl,
+		repo:     repo,
+		hub:      hub,
+		logger:   logger,
+		done:     make(chan struct{}),
+	}
+	go l.run()
+
+	return l, nil
+}
+
+// Close stops the listener
+func (l *Listener) Close() error {
+	err := l.listener.Close()
+	<-l.done
+	return err
+}
+
+func (l *Listener) run() {
+	defer close(l.done)
+
+	for n := range l.listener.Notify {
+		// A nil notification means the connection was re-established; messages sent while
+		// it was down are not replayed and clients pick them up on their next inbox fetch
+		if n == nil {
+			continue
+		}
+
+		var payload struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal([]byte(n.Extra), &payload); err != nil {
+			l.logger.WithError(err).Error("Invalid inbox notification payload")
+			continue
+		}
+
+		message, err := l.repo.GetMessageByID(payload.ID)
+		if err != nil {
+			l.logger.WithError(err).WithField("inbox_message_id", payload.ID).Error("Failed to load inbox message")
+			continue
+		}
+
+		l.hub.Publish(message)
+	}
+}
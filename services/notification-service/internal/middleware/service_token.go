@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireServiceToken returns a middleware that rejects requests without the token shared
+// with the other services in the X-Service-Token header. Routes that trust identity
+// headers such as X-User-ID use it so that only the gateway can set them, and routes
+// meant only for the other services use it to keep everyone else out.
+func RequireServiceToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Service-Token")), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid service token",
+			})
+			return
+		}
+		c.Next()
+	}
+}
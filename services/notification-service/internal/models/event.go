@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// User lifecycle event types published by user-service
+const (
+	EventUserCreated               = "user.created"
+	EventUserEmailChanged          = "user.email_changed"
+	EventUserVerificationRequested = "user.verification_requested"
+	EventPasswordResetRequested    = "user.password_reset_requested"
+	EventUserErased                = "user.erased"
+)
+
+// UserEvent is a user lifecycle event delivered by user-service
+type UserEvent struct {
+	ID         string        `json:"id" binding:"required"`
+	Type       string        `json:"type" binding:"required"`
+	UserID     string        `json:"user_id" binding:"required"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	Data       UserEventData `json:"data"`
+}
+
+// UserEventData is the user state carried by a user event
+type UserEventData struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	OldEmail string `json:"old_email"`
+	Link     string `json:"link"`
+}
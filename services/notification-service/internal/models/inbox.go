@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// InboxMessage is a notification delivered to a user's in-app inbox
+type InboxMessage struct {
+	ID             string     `db:"id" json:"id"`
+	UserID         string     `db:"user_id" json:"user_id"`
+	NotificationID *string    `db:"notification_id" json:"notification_id,omitempty"`
+	Subject        string     `db:"subject" json:"subject"`
+	Body           string     `db:"body" json:"body"`
+	Data           JSONMap    `db:"data" json:"data"`
+	ReadAt         *time.Time `db:"read_at" json:"read_at"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+}
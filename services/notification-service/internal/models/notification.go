@@ -0,0 +1,109 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Notification statuses
+const (
+	StatusScheduled  = "scheduled"
+	StatusQueued     = "queued"
+	StatusProcessing = "processing"
+	StatusRetrying   = "retrying"
+	StatusHeld       = "held"
+	StatusSent       = "sent"
+	StatusSuppressed = "suppressed"
+	StatusDead       = "dead"
+	StatusCancelled  = "cancelled"
+	// StatusBatched notifications wait to be delivered as part of a digest
+	StatusBatched = "batched"
+	// StatusDigested notifications were delivered as part of the digest named by DigestID
+	StatusDigested = "digested"
+)
+
+// CategoryDigest is the category of digest notifications
+const CategoryDigest = "digest"
+
+// Notification represents a notification and its delivery status
+type Notification struct {
+	ID              string     `db:"id" json:"id"`
+	UserID          *string    `db:"user_id" json:"user_id,omitempty"`
+	Recipient       string     `db:"recipient" json:"recipient"`
+	Channel         string     `db:"channel" json:"channel"`
+	Category        string     `db:"category" json:"category,omitempty"`
+	Critical        bool       `db:"critical" json:"critical"`
+	Sensitive       bool       `db:"sensitive" json:"sensitive"`
+	DedupKey        *string    `db:"dedup_key" json:"dedup_key,omitempty"`
+	DigestID        *string    `db:"digest_id" json:"digest_id,omitempty"`
+	Template        string     `db:"template" json:"template"`
+	TemplateVersion *int       `db:"template_version" json:"template_version,omitempty"`
+	Locale          string     `db:"locale" json:"locale"`
+	Data            JSONMap    `db:"data" json:"data"`
+	Subject         string     `db:"subject" json:"subject"`
+	TextBody        string     `db:"text_body" json:"text_body"`
+	HTMLBody        string     `db:"html_body" json:"html_body,omitempty"`
+	Status          string     `db:"status" json:"status"`
+	StatusReason    *string    `db:"status_reason" json:"status_reason,omitempty"`
+	Error           *string    `db:"error" json:"error,omitempty"`
+	Attempts        int        `db:"attempts" json:"attempts"`
+	SendAt          *time.Time `db:"send_at" json:"send_at,omitempty"`
+	NextAttemptAt   time.Time  `db:"next_attempt_at" json:"next_attempt_at"`
+	LockedUntil     *time.Time `db:"locked_until" json:"-"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
+	SentAt          *time.Time `db:"sent_at" json:"sent_at,omitempty"`
+	RedactedAt      *time.Time `db:"redacted_at" json:"redacted_at,omitempty"`
+}
+
+// CreateNotificationRequest represents a request to send a notification
+type CreateNotificationRequest struct {
+	UserID    string                 `json:"user_id"`
+	Recipient string                 `json:"recipient" binding:"required"`
+	Channel   string                 `json:"channel" binding:"required"`
+	Category  string                 `json:"category"`
+	Critical  bool                   `json:"critical"`
+	Sensitive bool                   `json:"sensitive"`
+	DedupKey  string                 `json:"dedup_key"`
+	Template  string                 `json:"template" binding:"required"`
+	Locale    string                 `json:"locale"`
+	Data      map[string]interface{} `json:"data"`
+	SendAt    *time.Time             `json:"send_at"`
+}
+
+// DigestGroup identifies the batched notifications that are delivered together in a digest
+type DigestGroup struct {
+	UserID    string `db:"user_id"`
+	Channel   string `db:"channel"`
+	Recipient string `db:"recipient"`
+}
+
+// JSONMap is a JSON object stored in a JSONB column
+type JSONMap map[string]interface{}
+
+// Value implements driver.Valuer
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements sql.Scanner
+func (m *JSONMap) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*m = JSONMap{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("unsupported type for JSONMap")
+	}
+	return json.Unmarshal(data, m)
+}
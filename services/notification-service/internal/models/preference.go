@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Digest frequencies
+const (
+	DigestImmediate = "immediate"
+	DigestHourly    = "hourly"
+	DigestDaily     = "daily"
+)
+
+// Preference holds a user's notification opt-outs, quiet hours and digest frequency
+type Preference struct {
+	UserID             string         `db:"user_id" json:"user_id"`
+	TimeZone           string         `db:"time_zone" json:"time_zone"`
+	QuietHoursStart    *string        `db:"quiet_hours_start" json:"quiet_hours_start"`
+	QuietHoursEnd      *string        `db:"quiet_hours_end" json:"quiet_hours_end"`
+	DisabledChannels   pq.StringArray `db:"disabled_channels" json:"disabled_channels"`
+	DisabledCategories pq.StringArray `db:"disabled_categories" json:"disabled_categories"`
+	Digest             string         `db:"digest" json:"digest"`
+	CreatedAt          time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// UpdatePreferenceRequest represents a request to replace a user's notification preferences
+type UpdatePreferenceRequest struct {
+	TimeZone           string   `json:"time_zone"`
+	QuietHoursStart    *string  `json:"quiet_hours_start"`
+	QuietHoursEnd      *string  `json:"quiet_hours_end"`
+	DisabledChannels   []string `json:"disabled_channels"`
+	DisabledCategories []string `json:"disabled_categories"`
+	Digest             string   `json:"digest"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Template represents one version of a localized notification template
+type Template struct {
+	ID         string         `db:"id" json:"id"`
+	Name       string         `db:"name" json:"name"`
+	Locale     string         `db:"locale" json:"locale"`
+	Version    int            `db:"version" json:"version"`
+	Subject    string         `db:"subject" json:"subject"`
+	TextBody   string         `db:"text_body" json:"text_body"`
+	HTMLBody   string         `db:"html_body" json:"html_body"`
+	Variables  pq.StringArray `db:"variables" json:"variables"`
+	SampleData JSONMap        `db:"sample_data" json:"sample_data"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+// CreateTemplateRequest represents a request to publish a new template version
+type CreateTemplateRequest struct {
+	Name       string                 `json:"name" binding:"required"`
+	Locale     string                 `json:"locale" binding:"required"`
+	Subject    string                 `json:"subject" binding:"required"`
+	TextBody   string                 `json:"text_body" binding:"required"`
+	HTMLBody   string                 `json:"html_body"`
+	Variables  []string               `json:"variables"`
+	SampleData map[string]interface{} `json:"sample_data"`
+}
+
+// PreviewTemplateRequest represents a request to render a template without sending it
+type PreviewTemplateRequest struct {
+	Locale string                 `json:"locale"`
+	Data   map[string]interface{} `json:"data"`
+}
+
+// RenderedTemplate is the output of rendering a template with data
+type RenderedTemplate struct {
+	Name     string `json:"name"`
+	Locale   string `json:"locale"`
+	Version  int    `json:"version"`
+	Subject  string `json:"subject"`
+	TextBody string `json:"text_body"`
+	HTMLBody string `json:"html_body,omitempty"`
+}
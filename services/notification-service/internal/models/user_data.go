@@ -0,0 +1,9 @@
+package models
+
+// UserData is everything the notification service holds about a user. Preference is
+// unset when the user never saved preferences.
+type UserData struct {
+	Notifications []Notification `json:"notifications"`
+	Inbox         []InboxMessage `json:"inbox"`
+	Preference    *Preference    `json:"preference"`
+}
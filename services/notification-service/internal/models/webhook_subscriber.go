@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// WebhookSubscriber is a webhook URL registered to receive signed deliveries
+type WebhookSubscriber struct {
+	ID                      string     `db:"id" json:"id"`
+	URL                     string     `db:"url" json:"url"`
+	Secret                  string     `db:"secret" json:"-"`
+	PreviousSecret          *string    `db:"previous_secret" json:"-"`
+	PreviousSecretExpiresAt *time.Time `db:"previous_secret_expires_at" json:"previous_secret_expires_at,omitempty"`
+	CreatedAt               time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt               time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// ActiveSecrets returns the secrets deliveries are currently signed with, newest first
+func (s *WebhookSubscriber) ActiveSecrets(now time.Time) []string {
+	secrets := []string{s.Secret}
+	if s.PreviousSecret != nil && s.PreviousSecretExpiresAt != nil && now.Before(*s.PreviousSecretExpiresAt) {
+		secrets = append(secrets, *s.PreviousSecret)
+	}
+	return secrets
+}
+
+// CreateWebhookSubscriberRequest represents a request to register a webhook URL
+type CreateWebhookSubscriberRequest struct {
+	URL string `json:"url" binding:"required,url"`
+}
+
+// RotateWebhookSecretRequest represents a request to rotate a subscriber's secret
+type RotateWebhookSecretRequest struct {
+	// GracePeriod is how long the previous secret stays valid, as a Go duration such as "24h"
+	GracePeriod string `json:"grace_period"`
+}
+
+// WebhookSubscriberSecret is returned once when a secret is issued
+type WebhookSubscriberSecret struct {
+	WebhookSubscriber
+	Secret string `json:"secret"`
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+)
+
+// InboxRepository handles database operations for in-app inbox messages
+type InboxRepository struct {
+	db *sqlx.DB
+}
+
+// NewInboxRepository creates a new InboxRepository
+func NewInboxRepository(db *sqlx.DB) *InboxRepository {
+	return &InboxRepository{
+		db: db,
+	}
+}
+
+// GetMessages gets a page of a user's inbox messages, newest first
+func (r *InboxRepository) GetMessages(userID string, unreadOnly bool, limit, offset int) ([]models.InboxMessage, error) {
+	messages := []models.InboxMessage{}
+	query := `
+		SELECT * FROM inbox_messages
+		WHERE user_id = $1 AND ($2 = FALSE OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+	err := r.db.Select(&messages, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbox messages: %w", err)
+	}
+	return messages, nil
+}
+
+// GetMessageByID gets an inbox message by ID
+func (r *InboxRepository) GetMessageByID(id string) (*models.InboxMessage, error) {
+	var message models.InboxMessage
+	query := `SELECT * FROM inbox_messages WHERE id = $1`
+	err := r.db.Get(&message, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbox message: %w", err)
+	}
+	return &message, nil
+}
+
+// CountUnread counts a user's unread inbox messages
+func (r *InboxRepository) CountUnread(userID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM inbox_messages WHERE user_id = $1 AND read_at IS NULL`
+	err := r.db.Get(&count, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread inbox messages: %w", err)
+	}
+	return count, nil
+}
+
+// CreateMessage creates a new inbox message. Redelivering the same notification is a no-op.
+func (r *InboxRepository) CreateMessage(message *models.InboxMessage) error {
+	query := `
+		INSERT INTO inbox_messages (id, user_id, notification_id, subject, body, data, created_at)
+		VALUES (:id, :user_id, :notification_id, :subject, :body, :data, :created_at)
+		ON CONFLICT (notification_id) DO NOTHING
+	`
+	_, err := r.db.NamedExec(query, message)
+	if err != nil {
+		return fmt.Errorf("failed to create inbox message: %w", err)
+	}
+	return nil
+}
+
+// MarkRead marks one of a user's inbox messages as read, reporting whether it exists
+func (r *InboxRepository) MarkRead(userID, id string, readAt time.Time) (bool, error) {
+	query := `
+		UPDATE inbox_messages
+		SET read_at = COALESCE(read_at, $3)
+		WHERE id = $1 AND user_id = $2
+	`
+	result, err := r.db.Exec(query, id, userID, readAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark inbox message read: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark inbox message read: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// MarkAllRead marks all of a user's inbox messages as read
+func (r *InboxRepository) MarkAllRead(userID string, readAt time.Time) error {
+	query := `UPDATE inbox_messages SET read_at = $2 WHERE user_id = $1 AND read_at IS NULL`
+	_, err := r.db.Exec(query, userID, readAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark inbox messages read: %w", err)
+	}
+	return nil
+}
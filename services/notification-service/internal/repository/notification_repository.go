@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+)
+
+// ErrLeaseLost is returned when recording the outcome of a claim whose lease has expired
+// and been taken over, by another worker's claim or by the expired lease sweep
+var ErrLeaseLost = errors.New("notification lease lost")
+
+// redactSensitive clears the data and rendered bodies of sensitive notifications, whose
+// links carry account tokens, as part of moving them to a final status
+const redactSensitive = `
+	data = CASE WHEN sensitive THEN '{}' ELSE data END,
+	text_body = CASE WHEN sensitive THEN '' ELSE text_body END,
+	html_body = CASE WHEN sensitive THEN '' ELSE html_body END,
+	redacted_at = CASE WHEN sensitive THEN NOW() ELSE redacted_at END`
+
+// NotificationRepository handles database operations for notifications. The notifications
+// table doubles as the delivery queue: workers claim due rows with FOR UPDATE SKIP LOCKED
+// and hold a lease on them while sending.
+type NotificationRepository struct {
+	db *sqlx.DB
+}
+
+// NewNotificationRepository creates a new NotificationRepository
+func NewNotificationRepository(db *sqlx.DB) *NotificationRepository {
+	return &NotificationRepository{
+		db: db,
+	}
+}
+
+// GetNotificationByID gets a notification by ID
+func (r *NotificationRepository) GetNotificationByID(id string) (*models.Notification, error) {
+	var notification models.Notification
+	query := `SELECT * FROM notifications WHERE id = $1`
+	err := r.db.Get(&notification, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+	return &notification, nil
+}
+
+// CreateNotification creates a new notification. It reports false without creating anything
+// when a notification with the same dedup key was already created for the recipient and channel.
+func (r *NotificationRepository) CreateNotification(notification *models.Notification) (bool, error) {
+	query := `
+		INSERT INTO notifications (
+			id, user_id, recipient, channel, category, critical, sensitive, dedup_key, template, template_version, locale, data,
+			subject, text_body, html_body, status, attempts, send_at, next_attempt_at, created_at, updated_at
+		)
+		VALUES (
+			:id, :user_id, :recipient, :channel, :category, :critical, :sensitive, :dedup_key, :template, :template_version, :locale, :data,
+			:subject, :text_body, :html_body, :status, :attempts, :send_at, :next_attempt_at, :created_at, :updated_at
+		)
+		ON CONFLICT (channel, recipient, dedup_key) WHERE dedup_key IS NOT NULL DO NOTHING
+	`
+	result, err := r.db.NamedExec(query, notification)
+	if err != nil {
+		return false, fmt.Errorf("failed to create notification: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to create notification: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// GetNotificationByDedupKey gets the notification created for a recipient and channel under a dedup key
+func (r *NotificationRepository) GetNotificationByDedupKey(channel, recipient, dedupKey string) (*models.Notification, error) {
+	var notification models.Notification
+	query := `SELECT * FROM notifications WHERE channel = $1 AND recipient = $2 AND dedup_key = $3`
+	err := r.db.Get(&notification, query, channel, recipient, dedupKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+	return &notification, nil
+}
+
+// ClaimNotifications leases up to limit notifications that are due for delivery. Each claim
+// counts as a delivery attempt. Row locks and the status change make sure that only one
+// worker, on any replica, claims a due notification. Rows whose lease expired without an
+// outcome being recorded, for example because a worker crashed, are claimed again unless
+// they were scheduled with send_at; those are dead-lettered by ExpireScheduledLeases
+// instead, so a scheduled notification is never dispatched twice.
+func (r *NotificationRepository) ClaimNotifications(limit int, lease time.Duration) ([]models.Notification, error) {
+	var notifications []models.Notification
+	query := `
+		UPDATE notifications
+		SET status = $1, attempts = attempts + 1, locked_until = NOW() + $2::bigint * INTERVAL '1 millisecond', updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE (status = ANY($3) AND next_attempt_at <= NOW())
+			   OR (status = $1 AND locked_until < NOW() AND send_at IS NULL)
+			ORDER BY next_attempt_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`
+	due := pq.Array([]string{models.StatusScheduled, models.StatusQueued, models.StatusRetrying, models.StatusHeld})
+	err := r.db.Select(&notifications, query, models.StatusProcessing, lease.Milliseconds(), due, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+// ExpireScheduledLeases dead-letters scheduled notifications whose lease expired without an
+// outcome being recorded. They may or may not have been sent, so they are left for an
+// operator to requeue rather than retried automatically. It returns how many were expired.
+func (r *NotificationRepository) ExpireScheduledLeases() (int64, error) {
+	query := `
+		UPDATE notifications
+		SET status = $2, error = $3, locked_until = NULL, updated_at = NOW(),` + redactSensitive + `
+		WHERE status = $1 AND locked_until < NOW() AND send_at IS NOT NULL
+	`
+	result, err := r.db.Exec(query, models.StatusProcessing, models.StatusDead,
+		"lease expired before the delivery outcome was recorded")
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire scheduled notifications: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// CancelNotification cancels a notification that has not been dispatched yet. It returns
+// sql.ErrNoRows when the notification does not exist or is no longer pending.
+func (r *NotificationRepository) CancelNotification(id string) (*models.Notification, error) {
+	var notification models.Notification
+	query := `
+		UPDATE notifications
+		SET status = $2, locked_until = NULL, updated_at = NOW(),` + redactSensitive + `
+		WHERE id = $1 AND status = ANY($3)
+		RETURNING *
+	`
+	pending := pq.Array([]string{models.StatusScheduled, models.StatusQueued, models.StatusRetrying, models.StatusHeld, models.StatusBatched})
+	err := r.db.Get(&notification, query, id, models.StatusCancelled, pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel notification: %w", err)
+	}
+	return &notification, nil
+}
+
+// MarkSent records a successful delivery of a claimed notification
+func (r *NotificationRepository) MarkSent(n *models.Notification, sentAt time.Time) error {
+	query := `
+		UPDATE notifications
+		SET status = $3, status_reason = NULL, error = NULL, locked_until = NULL, sent_at = $4, updated_at = $4,` + redactSensitive + `
+		WHERE id = $1 AND locked_until = $2
+	`
+	return r.markClaimed("mark notification sent", n, query, models.StatusSent, sentAt)
+}
+
+// MarkRetry records a failed attempt of a claimed notification and schedules the next one
+func (r *NotificationRepository) MarkRetry(n *models.Notification, errMsg string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE notifications
+		SET status = $3, error = $4, locked_until = NULL, next_attempt_at = $5, updated_at = NOW()
+		WHERE id = $1 AND locked_until = $2
+	`
+	return r.markClaimed("schedule notification retry", n, query, models.StatusRetrying, errMsg, nextAttemptAt)
+}
+
+// MarkHeld defers a claimed notification until the given time without counting the claim
+// as an attempt
+func (r *NotificationRepository) MarkHeld(n *models.Notification, reason string, until time.Time) error {
+	query := `
+		UPDATE notifications
+		SET status = $3, status_reason = $4, attempts = GREATEST(attempts - 1, 0),
+			locked_until = NULL, next_attempt_at = $5, updated_at = NOW()
+		WHERE id = $1 AND locked_until = $2
+	`
+	return r.markClaimed("hold notification", n, query, models.StatusHeld, reason, until)
+}
+
+// MarkBatched sets a claimed notification aside for the digest due at the given time
+// without counting the claim as an attempt
+func (r *NotificationRepository) MarkBatched(n *models.Notification, due time.Time) error {
+	query := `
+		UPDATE notifications
+		SET status = $3, status_reason = NULL, attempts = GREATEST(attempts - 1, 0),
+			locked_until = NULL, next_attempt_at = $4, updated_at = NOW()
+		WHERE id = $1 AND locked_until = $2
+	`
+	return r.markClaimed("batch notification", n, query, models.StatusBatched, due)
+}
+
+// GetDigestGroups gets the recipients with batched notifications. With a user ID it returns
+// all of that user's groups; otherwise it returns the groups whose oldest digest is due.
+func (r *NotificationRepository) GetDigestGroups(userID string) ([]models.DigestGroup, error) {
+	groups := []models.DigestGroup{}
+	query := `
+		SELECT user_id, channel, recipient FROM notifications
+		WHERE status = $1 AND user_id IS NOT NULL AND ($2 = '' OR user_id = $2)
+		GROUP BY user_id, channel, recipient
+		HAVING $2 <> '' OR MIN(next_attempt_at) <= NOW()
+	`
+	err := r.db.Select(&groups, query, models.StatusBatched, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digest groups: %w", err)
+	}
+	return groups, nil
+}
+
+// CreateDigest replaces a group's batched notifications with a single digest notification.
+// build receives the batched notifications, oldest first, and returns the digest to queue.
+// The batch is locked while the digest is built, so concurrent callers cannot digest the
+// same notifications twice; a nil digest is returned when there was nothing to digest.
+func (r *NotificationRepository) CreateDigest(group models.DigestGroup, build func([]models.Notification) (*models.Notification, error)) (*models.Notification, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create digest: %w", err)
+	}
+	defer tx.Rollback()
+
+	var items []models.Notification
+	query := `
+		SELECT * FROM notifications
+		WHERE status = $1 AND user_id = $2 AND channel = $3 AND recipient = $4
+		ORDER BY created_at
+		FOR UPDATE SKIP LOCKED
+	`
+	if err := tx.Select(&items, query, models.StatusBatched, group.UserID, group.Channel, group.Recipient); err != nil {
+		return nil, fmt.Errorf("failed to get batched notifications: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	digest, err := build(items)
+	if err != nil {
+		return nil, err
+	}
+
+	insert := `
+		INSERT INTO notifications (
+			id, user_id, recipient, channel, category, critical, template, template_version, locale, data,
+			subject, text_body, html_body, status, attempts, next_attempt_at, created_at, updated_at
+		)
+		VALUES (
+			:id, :user_id, :recipient, :channel, :category, :critical, :template, :template_version, :locale, :data,
+			:subject, :text_body, :html_body, :status, :attempts, :next_attempt_at, :created_at, :updated_at
+		)
+	`
+	if _, err := tx.NamedExec(insert, digest); err != nil {
+		return nil, fmt.Errorf("failed to create digest: %w", err)
+	}
+
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	update := `
+		UPDATE notifications
+		SET status = $1, digest_id = $2, updated_at = NOW()
+		WHERE id = ANY($3)
+	`
+	if _, err := tx.Exec(update, models.StatusDigested, digest.ID, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to mark notifications digested: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to create digest: %w", err)
+	}
+	return digest, nil
+}
+
+// MarkSuppressed records that a claimed notification was not sent and why
+func (r *NotificationRepository) MarkSuppressed(n *models.Notification, reason string) error {
+	query := `
+		UPDATE notifications
+		SET status = $3, status_reason = $4, attempts = GREATEST(attempts - 1, 0),
+			locked_until = NULL, updated_at = NOW(),` + redactSensitive + `
+		WHERE id = $1 AND locked_until = $2
+	`
+	return r.markClaimed("suppress notification", n, query, models.StatusSuppressed, reason)
+}
+
+// MarkDead moves a claimed notification to the dead-letter state
+func (r *NotificationRepository) MarkDead(n *models.Notification, errMsg string) error {
+	query := `
+		UPDATE notifications
+		SET status = $3, error = $4, locked_until = NULL, updated_at = NOW(),` + redactSensitive + `
+		WHERE id = $1 AND locked_until = $2
+	`
+	return r.markClaimed("dead-letter notification", n, query, models.StatusDead, errMsg)
+}
+
+// markClaimed records the outcome of a claim with query, whose first two parameters are
+// the notification's ID and lease. Every claim sets a new lease, so the update only
+// applies while n's claim is the current one; otherwise it returns ErrLeaseLost and the
+// outcome recorded by whoever took the notification over stands.
+func (r *NotificationRepository) markClaimed(action string, n *models.Notification, query string, args ...interface{}) error {
+	result, err := r.db.Exec(query, append([]interface{}{n.ID, n.LockedUntil}, args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	if affected == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// GetDeadLetters gets dead-lettered notifications, most recent first
+func (r *NotificationRepository) GetDeadLetters(limit, offset int) ([]models.Notification, error) {
+	notifications := []models.Notification{}
+	query := `
+		SELECT * FROM notifications
+		WHERE status = $1
+		ORDER BY updated_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	err := r.db.Select(&notifications, query, models.StatusDead, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letters: %w", err)
+	}
+	return notifications, nil
+}
+
+// RequeueDeadLetter puts a dead-lettered notification back on the queue with a fresh attempt
+// count. It returns sql.ErrNoRows when the notification does not exist, is not dead or had
+// its content redacted.
+func (r *NotificationRepository) RequeueDeadLetter(id string) (*models.Notification, error) {
+	var notification models.Notification
+	query := `
+		UPDATE notifications
+		SET status = $2, attempts = 0, error = NULL, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = $3 AND redacted_at IS NULL
+		RETURNING *
+	`
+	err := r.db.Get(&notification, query, id, models.StatusQueued, models.StatusDead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to requeue notification: %w", err)
+	}
+	return &notification, nil
+}
+
+// CountByStatus returns the number of notifications in each of the given statuses
+func (r *NotificationRepository) CountByStatus(statuses ...string) (map[string]int, error) {
+	var rows []struct {
+		Status string `db:"status"`
+		Count  int    `db:"count"`
+	}
+	query := `SELECT status, COUNT(*) AS count FROM notifications WHERE status = ANY($1) GROUP BY status`
+	if err := r.db.Select(&rows, query, pq.Array(statuses)); err != nil {
+		return nil, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	counts := make(map[string]int, len(statuses))
+	for _, status := range statuses {
+		counts[status] = 0
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,345 @@
+package repository_test
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/notification-service/internal/database"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+	"github.com/yourusername/go-microservices/notification-service/internal/repository"
+)
+
+// openTestDB connects to the Postgres database at TEST_DATABASE_URL after applying the
+// migrations to it. Their version is tracked in a table of its own, as other services'
+// tests share the database. The notification queue is emptied first, so claims only see
+// the notifications a test creates.
+func openTestDB(t *testing.T) *sqlx.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	migrationsURL, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := migrationsURL.Query()
+	query.Set("x-migrations-table", "notification_schema_migrations")
+	migrationsURL.RawQuery = query.Encode()
+	m, err := migrate.New("file://../../migrations", migrationsURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatal(err)
+	}
+	m.Close()
+
+	db, err := database.Connect(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec(`TRUNCATE notifications CASCADE`); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newNotification returns a queued notification that is due now
+func newNotification() *models.Notification {
+	now := time.Now()
+	return &models.Notification{
+		ID:            uuid.New().String(),
+		Recipient:     uuid.New().String() + "@example.com",
+		Channel:       "log",
+		Template:      "welcome",
+		Locale:        "en",
+		Data:          models.JSONMap{},
+		Status:        models.StatusQueued,
+		NextAttemptAt: now.Add(-time.Second),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+func create(t *testing.T, repo *repository.NotificationRepository, n *models.Notification) *models.Notification {
+	t.Helper()
+	if created, err := repo.CreateNotification(n); err != nil || !created {
+		t.Fatalf("CreateNotification = %v, %v; want true", created, err)
+	}
+	return n
+}
+
+func TestCreateNotificationDedupKey(t *testing.T) {
+	repo := repository.NewNotificationRepository(openTestDB(t))
+
+	key := "event-1"
+	first := newNotification()
+	first.DedupKey = &key
+	create(t, repo, first)
+
+	repeat := *first
+	repeat.ID = uuid.New().String()
+	if created, err := repo.CreateNotification(&repeat); err != nil || created {
+		t.Errorf("CreateNotification with a used dedup key = %v, %v; want false", created, err)
+	}
+
+	other := *first
+	other.ID, other.Channel = uuid.New().String(), "email"
+	if created, err := repo.CreateNotification(&other); err != nil || !created {
+		t.Errorf("CreateNotification with the key on another channel = %v, %v; want true", created, err)
+	}
+
+	existing, err := repo.GetNotificationByDedupKey(first.Channel, first.Recipient, key)
+	if err != nil || existing.ID != first.ID {
+		t.Errorf("GetNotificationByDedupKey = %+v, %v; want %s", existing, err, first.ID)
+	}
+}
+
+func TestClaimNotificationsSkipsLockedRows(t *testing.T) {
+	repo := repository.NewNotificationRepository(openTestDB(t))
+
+	const due = 40
+	for i := 0; i < due; i++ {
+		create(t, repo, newNotification())
+	}
+	notDue := newNotification()
+	notDue.NextAttemptAt = time.Now().Add(time.Hour)
+	create(t, repo, notDue)
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		claimed = make(map[string]int)
+	)
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				batch, err := repo.ClaimNotifications(3, time.Minute)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if len(batch) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, n := range batch {
+					claimed[n.ID]++
+					if n.Status != models.StatusProcessing || n.Attempts != 1 || n.LockedUntil == nil {
+						t.Errorf("claimed %s with status %s, %d attempts and lease %v", n.ID, n.Status, n.Attempts, n.LockedUntil)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(claimed) != due {
+		t.Errorf("claimed %d notifications, want %d", len(claimed), due)
+	}
+	for id, count := range claimed {
+		if count != 1 {
+			t.Errorf("notification %s was claimed %d times", id, count)
+		}
+	}
+	if claimed[notDue.ID] != 0 {
+		t.Errorf("claimed %s before it was due", notDue.ID)
+	}
+}
+
+func TestExpiredLeaseIsReclaimed(t *testing.T) {
+	repo := repository.NewNotificationRepository(openTestDB(t))
+	n := create(t, repo, newNotification())
+
+	first, err := repo.ClaimNotifications(10, time.Millisecond)
+	if err != nil || len(first) != 1 || first[0].ID != n.ID {
+		t.Fatalf("ClaimNotifications = %v, %v; want %s", first, err, n.ID)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	second, err := repo.ClaimNotifications(10, time.Minute)
+	if err != nil || len(second) != 1 || second[0].ID != n.ID {
+		t.Fatalf("ClaimNotifications after the lease expired = %v, %v; want %s", second, err, n.ID)
+	}
+	if second[0].Attempts != 2 {
+		t.Errorf("attempts = %d, want 2", second[0].Attempts)
+	}
+	if again, err := repo.ClaimNotifications(10, time.Minute); err != nil || len(again) != 0 {
+		t.Errorf("ClaimNotifications under a live lease = %v, %v; want nothing", again, err)
+	}
+
+	// Only the current claim may record an outcome
+	if err := repo.MarkSent(&first[0], time.Now()); !errors.Is(err, repository.ErrLeaseLost) {
+		t.Errorf("MarkSent with the expired claim: got %v, want ErrLeaseLost", err)
+	}
+	if err := repo.MarkSent(&second[0], time.Now()); err != nil {
+		t.Errorf("MarkSent with the current claim: %v", err)
+	}
+	if err := repo.MarkRetry(&second[0], "late", time.Now()); !errors.Is(err, repository.ErrLeaseLost) {
+		t.Errorf("MarkRetry after MarkSent: got %v, want ErrLeaseLost", err)
+	}
+
+	sent, err := repo.GetNotificationByID(n.ID)
+	if err != nil || sent.Status != models.StatusSent {
+		t.Errorf("GetNotificationByID = %+v, %v; want status %s", sent, err, models.StatusSent)
+	}
+}
+
+func TestDigestGroups(t *testing.T) {
+	repo := repository.NewNotificationRepository(openTestDB(t))
+
+	batched := func(userID string, due time.Time) *models.Notification {
+		n := newNotification()
+		n.UserID, n.Status, n.NextAttemptAt = &userID, models.StatusBatched, due
+		return create(t, repo, n)
+	}
+	dueUser, laterUser := uuid.New().String(), uuid.New().String()
+	due := batched(dueUser, time.Now().Add(-time.Minute))
+	batched(laterUser, time.Now().Add(time.Hour))
+
+	groups, err := repo.GetDigestGroups("")
+	if err != nil || len(groups) != 1 || groups[0].UserID != dueUser {
+		t.Fatalf("GetDigestGroups = %+v, %v; want the group of %s", groups, err, dueUser)
+	}
+	// Flushing a user's digests ignores when they are due
+	if flushed, err := repo.GetDigestGroups(laterUser); err != nil || len(flushed) != 1 {
+		t.Errorf("GetDigestGroups(%s) = %+v, %v; want one group", laterUser, flushed, err)
+	}
+
+	build := func(items []models.Notification) (*models.Notification, error) {
+		digest := newNotification()
+		digest.UserID, digest.Recipient, digest.Category = items[0].UserID, items[0].Recipient, models.CategoryDigest
+		return digest, nil
+	}
+	var (
+		wg      sync.WaitGroup
+		digests = make(chan *models.Notification, 4)
+	)
+	for i := 0; i < cap(digests); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			digest, err := repo.CreateDigest(groups[0], build)
+			if err != nil {
+				t.Error(err)
+			}
+			digests <- digest
+		}()
+	}
+	wg.Wait()
+	close(digests)
+
+	var created []*models.Notification
+	for digest := range digests {
+		if digest != nil {
+			created = append(created, digest)
+		}
+	}
+	if len(created) != 1 {
+		t.Fatalf("concurrent CreateDigest created %d digests, want 1", len(created))
+	}
+
+	digested, err := repo.GetNotificationByID(due.ID)
+	if err != nil || digested.Status != models.StatusDigested || digested.DigestID == nil || *digested.DigestID != created[0].ID {
+		t.Errorf("GetNotificationByID = %+v, %v; want digested into %s", digested, err, created[0].ID)
+	}
+	if groups, err := repo.GetDigestGroups(""); err != nil || len(groups) != 0 {
+		t.Errorf("GetDigestGroups after the digest = %+v, %v; want none", groups, err)
+	}
+}
+
+func TestScheduledLeaseIsNotReclaimed(t *testing.T) {
+	repo := repository.NewNotificationRepository(openTestDB(t))
+
+	scheduled := func(sendAt time.Time) *models.Notification {
+		n := newNotification()
+		n.Status, n.SendAt, n.NextAttemptAt = models.StatusScheduled, &sendAt, sendAt
+		return create(t, repo, n)
+	}
+	later := scheduled(time.Now().Add(time.Hour))
+	due := scheduled(time.Now().Add(-time.Second))
+
+	claimed, err := repo.ClaimNotifications(10, time.Millisecond)
+	if err != nil || len(claimed) != 1 || claimed[0].ID != due.ID {
+		t.Fatalf("ClaimNotifications = %v, %v; want only %s, not %s", claimed, err, due.ID, later.ID)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	// A scheduled notification may already have gone out, so an expired lease is not reclaimed
+	if again, err := repo.ClaimNotifications(10, time.Minute); err != nil || len(again) != 0 {
+		t.Errorf("ClaimNotifications after the lease expired = %v, %v; want nothing", again, err)
+	}
+	expired, err := repo.ExpireScheduledLeases()
+	if err != nil || expired != 1 {
+		t.Errorf("ExpireScheduledLeases = %d, %v; want 1", expired, err)
+	}
+	if err := repo.MarkSent(&claimed[0], time.Now()); !errors.Is(err, repository.ErrLeaseLost) {
+		t.Errorf("MarkSent after the lease was expired: got %v, want ErrLeaseLost", err)
+	}
+
+	dead, err := repo.GetNotificationByID(due.ID)
+	if err != nil || dead.Status != models.StatusDead {
+		t.Errorf("GetNotificationByID = %+v, %v; want status %s", dead, err, models.StatusDead)
+	}
+	pending, err := repo.GetNotificationByID(later.ID)
+	if err != nil || pending.Status != models.StatusScheduled {
+		t.Errorf("GetNotificationByID = %+v, %v; want status %s", pending, err, models.StatusScheduled)
+	}
+}
+
+func TestSensitiveNotificationsAreRedacted(t *testing.T) {
+	repo := repository.NewNotificationRepository(openTestDB(t))
+
+	newLink := func(sensitive bool) *models.Notification {
+		n := newNotification()
+		n.Sensitive = sensitive
+		n.Data = models.JSONMap{"link": "https://example.com/reset?token=secret"}
+		n.TextBody, n.HTMLBody = "Reset: https://example.com/reset?token=secret", "<a>reset</a>"
+		return create(t, repo, n)
+	}
+	sensitive, plain := newLink(true), newLink(false)
+
+	claimed, err := repo.ClaimNotifications(10, time.Minute)
+	if err != nil || len(claimed) != 2 {
+		t.Fatalf("ClaimNotifications = %v, %v; want 2 notifications", claimed, err)
+	}
+	for i := range claimed {
+		if err := repo.MarkSent(&claimed[i], time.Now()); err != nil {
+			t.Fatalf("MarkSent: %v", err)
+		}
+	}
+
+	redacted, err := repo.GetNotificationByID(sensitive.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(redacted.Data) != 0 || redacted.TextBody != "" || redacted.HTMLBody != "" || redacted.RedactedAt == nil {
+		t.Errorf("sent sensitive notification kept its content: %+v", redacted)
+	}
+	if redacted.Subject != sensitive.Subject {
+		t.Errorf("subject = %q, want %q", redacted.Subject, sensitive.Subject)
+	}
+
+	kept, err := repo.GetNotificationByID(plain.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept.Data["link"] == nil || kept.TextBody != plain.TextBody || kept.RedactedAt != nil {
+		t.Errorf("sent notification lost its content: %+v", kept)
+	}
+}
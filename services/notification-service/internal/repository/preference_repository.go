@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+)
+
+// PreferenceRepository handles database operations for notification preferences
+type PreferenceRepository struct {
+	db *sqlx.DB
+}
+
+// NewPreferenceRepository creates a new PreferenceRepository
+func NewPreferenceRepository(db *sqlx.DB) *PreferenceRepository {
+	return &PreferenceRepository{
+		db: db,
+	}
+}
+
+// GetPreference gets a user's notification preferences
+func (r *PreferenceRepository) GetPreference(userID string) (*models.Preference, error) {
+	var preference models.Preference
+	query := `SELECT * FROM notification_preferences WHERE user_id = $1`
+	err := r.db.Get(&preference, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	return &preference, nil
+}
+
+// UpsertPreference creates or replaces a user's notification preferences and loads the
+// stored row back into preference, so a replacement keeps its original created_at
+func (r *PreferenceRepository) UpsertPreference(preference *models.Preference) error {
+	query := `
+		INSERT INTO notification_preferences (
+			user_id, time_zone, quiet_hours_start, quiet_hours_end,
+			disabled_channels, disabled_categories, digest, created_at, updated_at
+		)
+		VALUES (
+			:user_id, :time_zone, :quiet_hours_start, :quiet_hours_end,
+			:disabled_channels, :disabled_categories, :digest, :created_at, :updated_at
+		)
+		ON CONFLICT (user_id) DO UPDATE
+		SET time_zone = EXCLUDED.time_zone,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			disabled_channels = EXCLUDED.disabled_channels,
+			disabled_categories = EXCLUDED.disabled_categories,
+			digest = EXCLUDED.digest,
+			updated_at = EXCLUDED.updated_at
+		RETURNING *
+	`
+	query, args, err := r.db.BindNamed(query, preference)
+	if err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	if err := r.db.Get(preference, query, args...); err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return nil
+}
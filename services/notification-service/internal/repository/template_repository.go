@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+)
+
+// TemplateRepository handles database operations for templates
+type TemplateRepository struct {
+	db *sqlx.DB
+}
+
+// NewTemplateRepository creates a new TemplateRepository
+func NewTemplateRepository(db *sqlx.DB) *TemplateRepository {
+	return &TemplateRepository{
+		db: db,
+	}
+}
+
+// GetTemplates gets every version of every template
+func (r *TemplateRepository) GetTemplates() ([]models.Template, error) {
+	var templates []models.Template
+	query := `SELECT * FROM templates ORDER BY name, locale, version DESC`
+	err := r.db.Select(&templates, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get templates: %w", err)
+	}
+	return templates, nil
+}
+
+// GetTemplatesByName gets every version of a template across all locales
+func (r *TemplateRepository) GetTemplatesByName(name string) ([]models.Template, error) {
+	var templates []models.Template
+	query := `SELECT * FROM templates WHERE name = $1 ORDER BY locale, version DESC`
+	err := r.db.Select(&templates, query, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get templates: %w", err)
+	}
+	return templates, nil
+}
+
+// GetLatestTemplate gets the latest version of a template for a locale
+func (r *TemplateRepository) GetLatestTemplate(name, locale string) (*models.Template, error) {
+	var template models.Template
+	query := `
+		SELECT * FROM templates
+		WHERE name = $1 AND locale = $2
+		ORDER BY version DESC
+		LIMIT 1
+	`
+	err := r.db.Get(&template, query, name, locale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	return &template, nil
+}
+
+// CreateTemplate stores a template as the next version for its name and locale.
+// Publishes of the same name and locale take turns on an advisory lock, so that
+// concurrent ones get consecutive versions instead of colliding on the same one.
+func (r *TemplateRepository) CreateTemplate(template *models.Template) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1 || '/' || $2))`, template.Name, template.Locale); err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+
+	query := `
+		INSERT INTO templates (id, name, locale, version, subject, text_body, html_body, variables, sample_data, created_at)
+		VALUES (
+			:id, :name, :locale,
+			(SELECT COALESCE(MAX(version), 0) + 1 FROM templates WHERE name = :name AND locale = :locale),
+			:subject, :text_body, :html_body, :variables, :sample_data, :created_at
+		)
+		RETURNING version
+	`
+	query, args, err := tx.BindNamed(query, template)
+	if err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+	if err := tx.Get(&template.Version, query, args...); err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+	return nil
+}
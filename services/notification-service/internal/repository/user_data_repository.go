@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+)
+
+// UserDataRepository reads and purges all data held about a user, for data export and
+// erasure requests
+type UserDataRepository struct {
+	db *sqlx.DB
+}
+
+// NewUserDataRepository creates a new UserDataRepository
+func NewUserDataRepository(db *sqlx.DB) *UserDataRepository {
+	return &UserDataRepository{
+		db: db,
+	}
+}
+
+// GetUserData gets a user's notifications, inbox messages and preferences
+func (r *UserDataRepository) GetUserData(userID string) (*models.UserData, error) {
+	data := &models.UserData{
+		Notifications: []models.Notification{},
+		Inbox:         []models.InboxMessage{},
+	}
+
+	query := `SELECT * FROM notifications WHERE user_id = $1 ORDER BY created_at, id`
+	if err := r.db.Select(&data.Notifications, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get user data: %w", err)
+	}
+
+	query = `SELECT * FROM inbox_messages WHERE user_id = $1 ORDER BY created_at, id`
+	if err := r.db.Select(&data.Inbox, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get user data: %w", err)
+	}
+
+	var preference models.Preference
+	query = `SELECT * FROM notification_preferences WHERE user_id = $1`
+	err := r.db.Get(&preference, query, userID)
+	switch {
+	case err == nil:
+		data.Preference = &preference
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("failed to get user data: %w", err)
+	}
+
+	return data, nil
+}
+
+// DeleteUserData deletes a user's notifications, including ones not yet delivered, their
+// inbox messages and their preferences. Deleting data that is already gone is not an error.
+func (r *UserDataRepository) DeleteUserData(userID string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to delete user data: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`DELETE FROM inbox_messages WHERE user_id = $1`,
+		`DELETE FROM notifications WHERE user_id = $1`,
+		`DELETE FROM notification_preferences WHERE user_id = $1`,
+	} {
+		if _, err := tx.Exec(query, userID); err != nil {
+			return fmt.Errorf("failed to delete user data: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to delete user data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+)
+
+// ErrDuplicateSubscriber is returned when registering a URL that already has a subscriber
+var ErrDuplicateSubscriber = errors.New("webhook URL is already registered")
+
+// WebhookSubscriberRepository handles database operations for webhook subscribers
+type WebhookSubscriberRepository struct {
+	db *sqlx.DB
+}
+
+// NewWebhookSubscriberRepository creates a new WebhookSubscriberRepository
+func NewWebhookSubscriberRepository(db *sqlx.DB) *WebhookSubscriberRepository {
+	return &WebhookSubscriberRepository{
+		db: db,
+	}
+}
+
+// GetSubscribers gets all webhook subscribers
+func (r *WebhookSubscriberRepository) GetSubscribers() ([]models.WebhookSubscriber, error) {
+	subscribers := []models.WebhookSubscriber{}
+	query := `SELECT * FROM webhook_subscribers ORDER BY created_at`
+	err := r.db.Select(&subscribers, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscribers: %w", err)
+	}
+	return subscribers, nil
+}
+
+// GetSubscriberByID gets a webhook subscriber by ID
+func (r *WebhookSubscriberRepository) GetSubscriberByID(id string) (*models.WebhookSubscriber, error) {
+	var subscriber models.WebhookSubscriber
+	query := `SELECT * FROM webhook_subscribers WHERE id = $1`
+	err := r.db.Get(&subscriber, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriber: %w", err)
+	}
+	return &subscriber, nil
+}
+
+// GetSubscriberByURL gets a webhook subscriber by its URL
+func (r *WebhookSubscriberRepository) GetSubscriberByURL(url string) (*models.WebhookSubscriber, error) {
+	var subscriber models.WebhookSubscriber
+	query := `SELECT * FROM webhook_subscribers WHERE url = $1`
+	err := r.db.Get(&subscriber, query, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriber: %w", err)
+	}
+	return &subscriber, nil
+}
+
+// CreateSubscriber creates a new webhook subscriber
+func (r *WebhookSubscriberRepository) CreateSubscriber(subscriber *models.WebhookSubscriber) error {
+	query := `
+		INSERT INTO webhook_subscribers (id, url, secret, created_at, updated_at)
+		VALUES (:id, :url, :secret, :created_at, :updated_at)
+	`
+	_, err := r.db.NamedExec(query, subscriber)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "webhook_subscribers_url_key" {
+			return ErrDuplicateSubscriber
+		}
+		return fmt.Errorf("failed to create webhook subscriber: %w", err)
+	}
+	return nil
+}
+
+// UpdateSecrets stores a subscriber's current and previous secrets
+func (r *WebhookSubscriberRepository) UpdateSecrets(subscriber *models.WebhookSubscriber) error {
+	query := `
+		UPDATE webhook_subscribers
+		SET secret = :secret, previous_secret = :previous_secret,
+			previous_secret_expires_at = :previous_secret_expires_at, updated_at = :updated_at
+		WHERE id = :id
+	`
+	_, err := r.db.NamedExec(query, subscriber)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook secrets: %w", err)
+	}
+	return nil
+}
+
+// DeleteSubscriber deletes a webhook subscriber
+func (r *WebhookSubscriberRepository) DeleteSubscriber(id string) error {
+	query := `DELETE FROM webhook_subscribers WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscriber: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+	"github.com/yourusername/go-microservices/notification-service/internal/repository"
+)
+
+// DigestService rolls batched notifications up into digest notifications
+type DigestService struct {
+	repo      *repository.NotificationRepository
+	templates *TemplateService
+	template  string
+}
+
+// NewDigestService creates a new DigestService that renders digests with the named template
+func NewDigestService(repo *repository.NotificationRepository, templates *TemplateService, template string) *DigestService {
+	return &DigestService{
+		repo:      repo,
+		templates: templates,
+		template:  template,
+	}
+}
+
+// SendDue queues a digest for every recipient whose oldest batched notification is due
+// and returns how many digests were queued
+func (s *DigestService) SendDue() (int, error) {
+	groups, err := s.repo.GetDigestGroups("")
+	if err != nil {
+		return 0, err
+	}
+	return s.send(groups)
+}
+
+// Flush queues digests for all of a user's batched notifications right away, for example
+// after they switch back to immediate delivery
+func (s *DigestService) Flush(userID string) error {
+	groups, err := s.repo.GetDigestGroups(userID)
+	if err != nil {
+		return err
+	}
+	_, err = s.send(groups)
+	return err
+}
+
+func (s *DigestService) send(groups []models.DigestGroup) (int, error) {
+	sent := 0
+	for _, group := range groups {
+		digest, err := s.repo.CreateDigest(group, s.build)
+		if err != nil {
+			return sent, fmt.Errorf("failed to send digest to %s: %w", group.Recipient, err)
+		}
+		if digest != nil {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// build renders the digest template over the batched notifications, in the locale of
+// the most recent one
+func (s *DigestService) build(items []models.Notification) (*models.Notification, error) {
+	latest := items[len(items)-1]
+
+	entries := make([]interface{}, len(items))
+	for i, item := range items {
+		entries[i] = map[string]interface{}{
+			"id":         item.ID,
+			"category":   item.Category,
+			"template":   item.Template,
+			"subject":    item.Subject,
+			"body":       item.TextBody,
+			"created_at": item.CreatedAt.Format(time.RFC3339),
+		}
+	}
+	data := map[string]interface{}{
+		"items": entries,
+		"count": len(items),
+	}
+
+	rendered, err := s.templates.Render(s.template, latest.Locale, data)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &models.Notification{
+		ID:              uuid.New().String(),
+		UserID:          latest.UserID,
+		Recipient:       latest.Recipient,
+		Channel:         latest.Channel,
+		Category:        models.CategoryDigest,
+		Template:        s.template,
+		TemplateVersion: &rendered.Version,
+		Locale:          rendered.Locale,
+		Data:            models.JSONMap(data),
+		Subject:         rendered.Subject,
+		TextBody:        rendered.TextBody,
+		HTMLBody:        rendered.HTMLBody,
+		Status:          models.StatusQueued,
+		NextAttemptAt:   now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}, nil
+}
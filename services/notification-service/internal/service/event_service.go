@@ -0,0 +1,120 @@
+package service
+
+import (
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+)
+
+// Templates and category of notifications sent for user lifecycle events
+const (
+	welcomeTemplate       = "welcome"
+	emailChangedTemplate  = "email_changed"
+	verifyEmailTemplate   = "verify_email"
+	resetPasswordTemplate = "password_reset"
+	accountCategory       = "account"
+)
+
+// EventService turns user lifecycle events into notifications
+type EventService struct {
+	notifications *NotificationService
+	userData      *UserDataService
+	channel       string
+}
+
+// NewEventService creates a new EventService that notifies users through channel
+func NewEventService(notifications *NotificationService, userData *UserDataService, channel string) *EventService {
+	return &EventService{
+		notifications: notifications,
+		userData:      userData,
+		channel:       channel,
+	}
+}
+
+// HandleUserEvent queues the notifications for a user event and returns them. Each
+// notification's dedup key is derived from the event ID, so redelivered events return
+// the notifications queued the first time instead of sending them again. Event types
+// without notifications are ignored. Erasure events delete everything held about the
+// user and queue nothing.
+func (s *EventService) HandleUserEvent(event *models.UserEvent) ([]models.Notification, error) {
+	var requests []*models.CreateNotificationRequest
+
+	switch event.Type {
+	case models.EventUserErased:
+		if err := s.userData.Erase(event.UserID); err != nil {
+			return nil, err
+		}
+	case models.EventUserCreated:
+		requests = append(requests, &models.CreateNotificationRequest{
+			UserID:    event.UserID,
+			Recipient: event.Data.Email,
+			Channel:   s.channel,
+			Category:  accountCategory,
+			Template:  welcomeTemplate,
+			DedupKey:  event.ID,
+			Data: map[string]interface{}{
+				"name": event.Data.Name,
+			},
+		})
+	case models.EventUserEmailChanged:
+		// Both addresses are told, so the owner of the old one can react to a takeover.
+		// These are security notices and skip digests and quiet hours.
+		for _, recipient := range []string{event.Data.OldEmail, event.Data.Email} {
+			if recipient == "" {
+				continue
+			}
+			requests = append(requests, &models.CreateNotificationRequest{
+				UserID:    event.UserID,
+				Recipient: recipient,
+				Channel:   s.channel,
+				Category:  accountCategory,
+				Critical:  true,
+				Template:  emailChangedTemplate,
+				DedupKey:  event.ID,
+				Data: map[string]interface{}{
+					"name":      event.Data.Name,
+					"old_email": event.Data.OldEmail,
+					"new_email": event.Data.Email,
+				},
+			})
+		}
+	case models.EventUserVerificationRequested:
+		requests = append(requests, &models.CreateNotificationRequest{
+			UserID:    event.UserID,
+			Recipient: event.Data.Email,
+			Channel:   s.channel,
+			Category:  accountCategory,
+			Critical:  true,
+			Sensitive: true,
+			Template:  verifyEmailTemplate,
+			DedupKey:  event.ID,
+			Data: map[string]interface{}{
+				"name": event.Data.Name,
+				"link": event.Data.Link,
+			},
+		})
+	case models.EventPasswordResetRequested:
+		requests = append(requests, &models.CreateNotificationRequest{
+			UserID:    event.UserID,
+			Recipient: event.Data.Email,
+			Channel:   s.channel,
+			Category:  accountCategory,
+			Critical:  true,
+			Sensitive: true,
+			Template:  resetPasswordTemplate,
+			DedupKey:  event.ID,
+			Data: map[string]interface{}{
+				"name": event.Data.Name,
+				"link": event.Data.Link,
+			},
+		})
+	}
+
+	notifications := []models.Notification{}
+	for _, req := range requests {
+		notification, _, err := s.notifications.CreateNotification(req)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, *notification)
+	}
+	return notifications, nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/go-microservices/notification-service/internal/channel"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+	"github.com/yourusername/go-microservices/notification-service/internal/repository"
+)
+
+// ErrInboxMessageNotFound is returned when a user has no inbox message with the given ID
+var ErrInboxMessageNotFound = errors.New("inbox message not found")
+
+// InboxService handles business logic for in-app inboxes
+type InboxService struct {
+	repo *repository.InboxRepository
+}
+
+// NewInboxService creates a new InboxService
+func NewInboxService(repo *repository.InboxRepository) *InboxService {
+	return &InboxService{
+		repo: repo,
+	}
+}
+
+// Deliver stores a message in the inbox of the user named as its recipient. Live streams
+// are notified through the database, so they receive it on whichever replica they use.
+func (s *InboxService) Deliver(msg *channel.Message) error {
+	notificationID := msg.ID
+	return s.repo.CreateMessage(&models.InboxMessage{
+		ID:             uuid.New().String(),
+		UserID:         msg.Recipient,
+		NotificationID: &notificationID,
+		Subject:        msg.Subject,
+		Body:           msg.Body,
+		Data:           models.JSONMap(msg.Data),
+		CreatedAt:      time.Now(),
+	})
+}
+
+// GetInbox gets a page of a user's inbox messages, newest first
+func (s *InboxService) GetInbox(userID string, unreadOnly bool, limit, offset int) ([]models.InboxMessage, error) {
+	return s.repo.GetMessages(userID, unreadOnly, limit, offset)
+}
+
+// CountUnread counts a user's unread inbox messages
+func (s *InboxService) CountUnread(userID string) (int, error) {
+	return s.repo.CountUnread(userID)
+}
+
+// MarkRead marks one of a user's inbox messages as read
+func (s *InboxService) MarkRead(userID, id string) error {
+	found, err := s.repo.MarkRead(userID, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrInboxMessageNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks all of a user's inbox messages as read
+func (s *InboxService) MarkAllRead(userID string) error {
+	return s.repo.MarkAllRead(userID, time.Now())
+}
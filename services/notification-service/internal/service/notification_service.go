@@ -0,0 +1,144 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/go-microservices/notification-service/internal/channel"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+	"github.com/yourusername/go-microservices/notification-service/internal/repository"
+)
+
+var (
+	// ErrUnknownChannel is returned when a notification requests a channel that is not registered
+	ErrUnknownChannel = errors.New("unknown channel")
+	// ErrNotCancellable is returned when cancelling a notification that was already dispatched or finished
+	ErrNotCancellable = errors.New("notification can no longer be cancelled")
+	// ErrRedacted is returned when requeuing a sensitive notification whose content was cleared
+	ErrRedacted = errors.New("notification content was redacted")
+)
+
+// NotificationService handles business logic for notifications
+type NotificationService struct {
+	repo      *repository.NotificationRepository
+	templates *TemplateService
+	channels  *channel.Registry
+}
+
+// NewNotificationService creates a new NotificationService
+func NewNotificationService(repo *repository.NotificationRepository, templates *TemplateService, channels *channel.Registry) *NotificationService {
+	return &NotificationService{
+		repo:      repo,
+		templates: templates,
+		channels:  channels,
+	}
+}
+
+// GetNotification gets a notification by ID
+func (s *NotificationService) GetNotification(id string) (*models.Notification, error) {
+	return s.repo.GetNotificationByID(id)
+}
+
+// CreateNotification renders and queues a notification. A repeated dedup key returns the
+// earlier notification and false.
+func (s *NotificationService) CreateNotification(req *models.CreateNotificationRequest) (*models.Notification, bool, error) {
+	if _, err := s.channels.Get(req.Channel); err != nil {
+		return nil, false, fmt.Errorf("%w: %s", ErrUnknownChannel, req.Channel)
+	}
+
+	data := req.Data
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	rendered, err := s.templates.Render(req.Template, req.Locale, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var userID, dedupKey *string
+	if req.UserID != "" {
+		userID = &req.UserID
+	}
+	if req.DedupKey != "" {
+		dedupKey = &req.DedupKey
+	}
+
+	now := time.Now()
+	status, nextAttemptAt := models.StatusQueued, now
+	var sendAt *time.Time
+	if req.SendAt != nil && req.SendAt.After(now) {
+		status, nextAttemptAt, sendAt = models.StatusScheduled, *req.SendAt, req.SendAt
+	}
+
+	notification := &models.Notification{
+		ID:              uuid.New().String(),
+		UserID:          userID,
+		Recipient:       req.Recipient,
+		Channel:         req.Channel,
+		Category:        req.Category,
+		Critical:        req.Critical,
+		Sensitive:       req.Sensitive,
+		DedupKey:        dedupKey,
+		Template:        req.Template,
+		TemplateVersion: &rendered.Version,
+		Locale:          rendered.Locale,
+		Data:            models.JSONMap(data),
+		Subject:         rendered.Subject,
+		TextBody:        rendered.TextBody,
+		HTMLBody:        rendered.HTMLBody,
+		Status:          status,
+		SendAt:          sendAt,
+		NextAttemptAt:   nextAttemptAt,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	created, err := s.repo.CreateNotification(notification)
+	if err != nil {
+		return nil, false, err
+	}
+	if !created {
+		existing, err := s.repo.GetNotificationByDedupKey(req.Channel, req.Recipient, req.DedupKey)
+		if err != nil {
+			return nil, false, err
+		}
+		return existing, false, nil
+	}
+
+	return notification, true, nil
+}
+
+// CancelNotification cancels a notification that has not been dispatched yet
+func (s *NotificationService) CancelNotification(id string) (*models.Notification, error) {
+	notification, err := s.repo.CancelNotification(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		existing, getErr := s.repo.GetNotificationByID(id)
+		if getErr != nil {
+			return nil, getErr
+		}
+		return nil, fmt.Errorf("%w: status is %s", ErrNotCancellable, existing.Status)
+	}
+	return notification, err
+}
+
+// GetDeadLetters gets a page of dead-lettered notifications
+func (s *NotificationService) GetDeadLetters(limit, offset int) ([]models.Notification, error) {
+	return s.repo.GetDeadLetters(limit, offset)
+}
+
+// RequeueDeadLetter puts a dead-lettered notification back on the queue. Sensitive
+// notifications cannot be requeued once dead, as their content has been redacted.
+func (s *NotificationService) RequeueDeadLetter(id string) (*models.Notification, error) {
+	notification, err := s.repo.RequeueDeadLetter(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		existing, getErr := s.repo.GetNotificationByID(id)
+		if getErr == nil && existing.Status == models.StatusDead && existing.RedactedAt != nil {
+			return nil, ErrRedacted
+		}
+	}
+	return notification, err
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+	"github.com/yourusername/go-microservices/notification-service/internal/repository"
+)
+
+const (
+	// quietHoursLayout is the clock format of quiet hours boundaries
+	quietHoursLayout = "15:04"
+	// dailyDigestHour is the local hour at which daily digests are sent
+	dailyDigestHour = 9
+)
+
+// digestChannels are the channels whose notifications can be rolled up into digests
+var digestChannels = map[string]bool{
+	"email":  true,
+	"in_app": true,
+}
+
+// ErrInvalidPreference is returned when preferences contain an unknown time zone or malformed quiet hours
+var ErrInvalidPreference = errors.New("invalid preference")
+
+// Delivery decisions made before a notification is sent
+const (
+	DecisionDeliver  = "deliver"
+	DecisionHold     = "hold"
+	DecisionSuppress = "suppress"
+	DecisionBatch    = "batch"
+)
+
+// Decision is the outcome of checking a notification against its recipient's preferences
+type Decision struct {
+	Action string
+	Reason string
+	// Until is when a held notification becomes deliverable or a batched one's digest is due
+	Until time.Time
+}
+
+// PreferenceService handles business logic for notification preferences
+type PreferenceService struct {
+	repo    *repository.PreferenceRepository
+	digests *DigestService
+}
+
+// NewPreferenceService creates a new PreferenceService
+func NewPreferenceService(repo *repository.PreferenceRepository, digests *DigestService) *PreferenceService {
+	return &PreferenceService{
+		repo:    repo,
+		digests: digests,
+	}
+}
+
+// GetPreference gets a user's preferences, falling back to defaults when none are stored
+func (s *PreferenceService) GetPreference(userID string) (*models.Preference, error) {
+	preference, err := s.repo.GetPreference(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.Preference{
+			UserID:             userID,
+			TimeZone:           "UTC",
+			DisabledChannels:   []string{},
+			DisabledCategories: []string{},
+			Digest:             models.DigestImmediate,
+		}, nil
+	}
+	return preference, err
+}
+
+// UpdatePreference validates and replaces a user's preferences. Switching to immediate
+// delivery flushes any notifications still waiting for a digest.
+func (s *PreferenceService) UpdatePreference(userID string, req *models.UpdatePreferenceRequest) (*models.Preference, error) {
+	timeZone := req.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %s", ErrInvalidPreference, timeZone)
+	}
+
+	if (req.QuietHoursStart == nil) != (req.QuietHoursEnd == nil) {
+		return nil, fmt.Errorf("%w: quiet hours need both a start and an end", ErrInvalidPreference)
+	}
+	for _, v := range []*string{req.QuietHoursStart, req.QuietHoursEnd} {
+		if v == nil {
+			continue
+		}
+		if _, err := time.Parse(quietHoursLayout, *v); err != nil {
+			return nil, fmt.Errorf("%w: quiet hours must be HH:MM, got %s", ErrInvalidPreference, *v)
+		}
+	}
+
+	digest := req.Digest
+	if digest == "" {
+		digest = models.DigestImmediate
+	}
+	switch digest {
+	case models.DigestImmediate, models.DigestHourly, models.DigestDaily:
+	default:
+		return nil, fmt.Errorf("%w: digest must be %s, %s or %s, got %s", ErrInvalidPreference,
+			models.DigestImmediate, models.DigestHourly, models.DigestDaily, digest)
+	}
+
+	now := time.Now()
+	preference := &models.Preference{
+		UserID:             userID,
+		TimeZone:           timeZone,
+		QuietHoursStart:    req.QuietHoursStart,
+		QuietHoursEnd:      req.QuietHoursEnd,
+		DisabledChannels:   req.DisabledChannels,
+		DisabledCategories: req.DisabledCategories,
+		Digest:             digest,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if preference.DisabledChannels == nil {
+		preference.DisabledChannels = []string{}
+	}
+	if preference.DisabledCategories == nil {
+		preference.DisabledCategories = []string{}
+	}
+
+	// Pending digests are flushed before saving, so that a failure leaves the old
+	// preference in place rather than failing a change that was stored. Notifications
+	// batched in between go out with their scheduled digest.
+	if digest == models.DigestImmediate {
+		if err := s.digests.Flush(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpsertPreference(preference); err != nil {
+		return nil, err
+	}
+
+	return preference, nil
+}
+
+// Check decides whether a notification may be sent now. Notifications without a user and
+// critical notifications, such as password resets, are always delivered right away: users
+// cannot opt out of them, batch them into digests or hold them for quiet hours. Sensitive
+// notifications are not batched either, as digests would keep a copy of their content.
+func (s *PreferenceService) Check(n *models.Notification, now time.Time) (*Decision, error) {
+	if n.UserID == nil || *n.UserID == "" || n.Critical {
+		return &Decision{Action: DecisionDeliver}, nil
+	}
+
+	preference, err := s.GetPreference(*n.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ch := range preference.DisabledChannels {
+		if ch == n.Channel {
+			return &Decision{Action: DecisionSuppress, Reason: fmt.Sprintf("user opted out of channel %s", ch)}, nil
+		}
+	}
+	if n.Category != "" {
+		for _, category := range preference.DisabledCategories {
+			if category == n.Category {
+				return &Decision{Action: DecisionSuppress, Reason: fmt.Sprintf("user opted out of category %s", category)}, nil
+			}
+		}
+	}
+
+	if n.Category != models.CategoryDigest && !n.Sensitive && digestChannels[n.Channel] {
+		if due, ok := digestDue(preference, now); ok {
+			return &Decision{Action: DecisionBatch, Reason: preference.Digest + " digest", Until: due}, nil
+		}
+	}
+
+	if end, ok := quietHoursEnd(preference, now); ok {
+		return &Decision{Action: DecisionHold, Reason: "quiet hours", Until: end}, nil
+	}
+
+	return &Decision{Action: DecisionDeliver}, nil
+}
+
+// digestDue reports whether the user receives digests and, if so, when the next one is
+// due: at the top of the next hour, or at dailyDigestHour in the user's time zone
+func digestDue(p *models.Preference, now time.Time) (time.Time, bool) {
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+
+	switch p.Digest {
+	case models.DigestHourly:
+		return time.Date(local.Year(), local.Month(), local.Day(), local.Hour()+1, 0, 0, 0, loc), true
+	case models.DigestDaily:
+		due := time.Date(local.Year(), local.Month(), local.Day(), dailyDigestHour, 0, 0, 0, loc)
+		if !due.After(local) {
+			due = time.Date(local.Year(), local.Month(), local.Day()+1, dailyDigestHour, 0, 0, 0, loc)
+		}
+		return due, true
+	}
+	return time.Time{}, false
+}
+
+// quietHoursEnd reports whether now falls within the user's quiet hours and, if so, when
+// they end. Windows may wrap past midnight, for example 22:00 to 07:00.
+func quietHoursEnd(p *models.Preference, now time.Time) (time.Time, bool) {
+	if p.QuietHoursStart == nil || p.QuietHoursEnd == nil {
+		return time.Time{}, false
+	}
+
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	start, err := time.Parse(quietHoursLayout, *p.QuietHoursStart)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := time.Parse(quietHoursLayout, *p.QuietHoursEnd)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	at := func(day time.Time, clock time.Time) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	}
+	startToday, endToday := at(local, start), at(local, end)
+
+	switch {
+	case startToday.Equal(endToday):
+		// An empty window never applies
+		return time.Time{}, false
+	case startToday.Before(endToday):
+		if !local.Before(startToday) && local.Before(endToday) {
+			return endToday, true
+		}
+	default:
+		if !local.Before(startToday) {
+			return at(local.AddDate(0, 0, 1), end), true
+		}
+		if local.Before(endToday) {
+			return endToday, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+)
+
+func clock(s string) *string {
+	return &s
+}
+
+func TestQuietHoursEnd(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeZone string
+		start    string
+		end      string
+		now      time.Time
+		want     time.Time
+		held     bool
+	}{
+		{
+			name: "before an overnight window", timeZone: "Europe/Berlin", start: "22:00", end: "07:00",
+			now: time.Date(2024, 6, 1, 19, 59, 0, 0, time.UTC),
+		},
+		{
+			name: "overnight window before midnight", timeZone: "Europe/Berlin", start: "22:00", end: "07:00",
+			now:  time.Date(2024, 6, 1, 21, 30, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 2, 5, 0, 0, 0, time.UTC), held: true,
+		},
+		{
+			name: "overnight window after midnight", timeZone: "Europe/Berlin", start: "22:00", end: "07:00",
+			now:  time.Date(2024, 6, 2, 3, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 2, 5, 0, 0, 0, time.UTC), held: true,
+		},
+		{
+			name: "overnight window has ended", timeZone: "Europe/Berlin", start: "22:00", end: "07:00",
+			now: time.Date(2024, 6, 2, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "local evening is the next day in UTC", timeZone: "America/New_York", start: "22:00", end: "07:00",
+			now:  time.Date(2024, 6, 2, 3, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 2, 11, 0, 0, 0, time.UTC), held: true,
+		},
+		{
+			name: "window spanning a daylight saving change", timeZone: "Europe/Berlin", start: "22:00", end: "07:00",
+			now:  time.Date(2024, 3, 30, 22, 30, 0, 0, time.UTC),
+			want: time.Date(2024, 3, 31, 5, 0, 0, 0, time.UTC), held: true,
+		},
+		{
+			name: "daytime window", timeZone: "UTC", start: "12:00", end: "14:00",
+			now:  time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 1, 14, 0, 0, 0, time.UTC), held: true,
+		},
+		{
+			name: "daytime window end is exclusive", timeZone: "UTC", start: "12:00", end: "14:00",
+			now: time.Date(2024, 6, 1, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "empty window", timeZone: "UTC", start: "09:00", end: "09:00",
+			now: time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "unknown time zone falls back to UTC", timeZone: "Mars/Olympus_Mons", start: "22:00", end: "07:00",
+			now:  time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 2, 7, 0, 0, 0, time.UTC), held: true,
+		},
+		{
+			name: "malformed clock", timeZone: "UTC", start: "10pm", end: "07:00",
+			now: time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &models.Preference{TimeZone: tt.timeZone, QuietHoursStart: clock(tt.start), QuietHoursEnd: clock(tt.end)}
+			got, held := quietHoursEnd(p, tt.now)
+			if held != tt.held || (held && !got.Equal(tt.want)) {
+				t.Errorf("quietHoursEnd at %v = %v, %v; want %v, %v", tt.now, got.UTC(), held, tt.want, tt.held)
+			}
+		})
+	}
+
+	if _, held := quietHoursEnd(&models.Preference{TimeZone: "UTC"}, time.Now()); held {
+		t.Error("quietHoursEnd without quiet hours: got held")
+	}
+}
+
+func TestDigestDue(t *testing.T) {
+	tests := []struct {
+		name     string
+		digest   string
+		timeZone string
+		now      time.Time
+		want     time.Time
+		batched  bool
+	}{
+		{
+			name: "immediate", digest: models.DigestImmediate, timeZone: "UTC",
+			now: time.Date(2024, 6, 1, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			name: "hourly", digest: models.DigestHourly, timeZone: "UTC",
+			now:  time.Date(2024, 6, 1, 10, 15, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 1, 11, 0, 0, 0, time.UTC), batched: true,
+		},
+		{
+			name: "hourly on the hour waits for the next one", digest: models.DigestHourly, timeZone: "UTC",
+			now:  time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 1, 11, 0, 0, 0, time.UTC), batched: true,
+		},
+		{
+			name: "hourly before midnight", digest: models.DigestHourly, timeZone: "UTC",
+			now:  time.Date(2024, 6, 1, 23, 30, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC), batched: true,
+		},
+		{
+			name: "hourly in a half-hour time zone", digest: models.DigestHourly, timeZone: "Asia/Kolkata",
+			now:  time.Date(2024, 6, 1, 10, 15, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 1, 10, 30, 0, 0, time.UTC), batched: true,
+		},
+		{
+			name: "daily before nine", digest: models.DigestDaily, timeZone: "UTC",
+			now:  time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC), batched: true,
+		},
+		{
+			name: "daily at nine waits for the next day", digest: models.DigestDaily, timeZone: "UTC",
+			now:  time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC), batched: true,
+		},
+		{
+			name: "daily in the user's time zone", digest: models.DigestDaily, timeZone: "America/New_York",
+			now:  time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 1, 13, 0, 0, 0, time.UTC), batched: true,
+		},
+		{
+			name: "daily after nine local time", digest: models.DigestDaily, timeZone: "Europe/Berlin",
+			now:  time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 2, 7, 0, 0, 0, time.UTC), batched: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &models.Preference{TimeZone: tt.timeZone, Digest: tt.digest}
+			got, batched := digestDue(p, tt.now)
+			if batched != tt.batched || (batched && !got.Equal(tt.want)) {
+				t.Errorf("digestDue at %v = %v, %v; want %v, %v", tt.now, got.UTC(), batched, tt.want, tt.batched)
+			}
+		})
+	}
+}
+
+func TestCheckDeliversCriticalNotifications(t *testing.T) {
+	// Critical notifications are decided without looking up the user's preferences
+	s := NewPreferenceService(nil, nil)
+	userID := "user-1"
+
+	decision, err := s.Check(&models.Notification{UserID: &userID, Channel: "email", Category: "account", Critical: true}, time.Now())
+	if err != nil || decision.Action != DecisionDeliver {
+		t.Errorf("Check = %+v, %v; want %s", decision, err, DecisionDeliver)
+	}
+}
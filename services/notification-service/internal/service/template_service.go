@@ -0,0 +1,115 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+	"github.com/yourusername/go-microservices/notification-service/internal/repository"
+	"github.com/yourusername/go-microservices/notification-service/internal/templating"
+)
+
+// ErrTemplateNotFound is returned when no template exists for any locale in the fallback chain
+var ErrTemplateNotFound = errors.New("template not found")
+
+// TemplateService handles business logic for templates
+type TemplateService struct {
+	repo          *repository.TemplateRepository
+	defaultLocale string
+}
+
+// NewTemplateService creates a new TemplateService
+func NewTemplateService(repo *repository.TemplateRepository, defaultLocale string) *TemplateService {
+	return &TemplateService{
+		repo:          repo,
+		defaultLocale: defaultLocale,
+	}
+}
+
+// GetTemplates gets every version of every template
+func (s *TemplateService) GetTemplates() ([]models.Template, error) {
+	return s.repo.GetTemplates()
+}
+
+// GetTemplateVersions gets every version of a template across all locales
+func (s *TemplateService) GetTemplateVersions(name string) ([]models.Template, error) {
+	templates, err := s.repo.GetTemplatesByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return nil, ErrTemplateNotFound
+	}
+	return templates, nil
+}
+
+// CreateTemplate validates a template and publishes it as a new version
+func (s *TemplateService) CreateTemplate(req *models.CreateTemplateRequest) (*models.Template, error) {
+	template := &models.Template{
+		ID:         uuid.New().String(),
+		Name:       req.Name,
+		Locale:     req.Locale,
+		Subject:    req.Subject,
+		TextBody:   req.TextBody,
+		HTMLBody:   req.HTMLBody,
+		Variables:  req.Variables,
+		SampleData: models.JSONMap(req.SampleData),
+		CreatedAt:  time.Now(),
+	}
+	if template.Variables == nil {
+		template.Variables = []string{}
+	}
+
+	if err := templating.Validate(template); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// Resolve finds the latest template version for the most specific available locale
+func (s *TemplateService) Resolve(name, locale string) (*models.Template, error) {
+	for _, l := range templating.LocaleChain(locale, s.defaultLocale) {
+		template, err := s.repo.GetLatestTemplate(name, l)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return template, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+}
+
+// Render resolves a template and renders it with data
+func (s *TemplateService) Render(name, locale string, data map[string]interface{}) (*models.RenderedTemplate, error) {
+	template, err := s.Resolve(name, locale)
+	if err != nil {
+		return nil, err
+	}
+	return templating.Render(template, data)
+}
+
+// Preview renders a template without sending it. The template's sample data is used
+// when the request does not supply any.
+func (s *TemplateService) Preview(name string, req *models.PreviewTemplateRequest) (*models.RenderedTemplate, error) {
+	template, err := s.Resolve(name, req.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	data := req.Data
+	if data == nil {
+		data = template.SampleData
+	}
+
+	return templating.Render(template, data)
+}
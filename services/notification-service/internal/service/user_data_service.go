@@ -0,0 +1,28 @@
+package service
+
+import (
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+	"github.com/yourusername/go-microservices/notification-service/internal/repository"
+)
+
+// UserDataService handles data export and erasure requests for users
+type UserDataService struct {
+	repo *repository.UserDataRepository
+}
+
+// NewUserDataService creates a new UserDataService
+func NewUserDataService(repo *repository.UserDataRepository) *UserDataService {
+	return &UserDataService{
+		repo: repo,
+	}
+}
+
+// Export gets everything held about a user
+func (s *UserDataService) Export(userID string) (*models.UserData, error) {
+	return s.repo.GetUserData(userID)
+}
+
+// Erase deletes everything held about a user. It is safe to repeat.
+func (s *UserDataService) Erase(userID string) error {
+	return s.repo.DeleteUserData(userID)
+}
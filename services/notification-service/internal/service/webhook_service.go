@@ -0,0 +1,133 @@
+package service
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/go-microservices/notification-service/internal/channel"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+	"github.com/yourusername/go-microservices/notification-service/internal/repository"
+)
+
+// defaultGracePeriod is how long a rotated-out secret keeps signing deliveries
+const defaultGracePeriod = 24 * time.Hour
+
+var (
+	// ErrSubscriberNotFound is returned when no webhook subscriber matches
+	ErrSubscriberNotFound = errors.New("webhook subscriber not found")
+	// ErrInvalidGracePeriod is returned when a rotation grace period cannot be parsed
+	ErrInvalidGracePeriod = errors.New("invalid grace period")
+	// ErrDuplicateSubscriber is returned when registering a URL that already has a subscriber
+	ErrDuplicateSubscriber = repository.ErrDuplicateSubscriber
+)
+
+// WebhookService handles business logic for webhook subscribers and their signing secrets
+type WebhookService struct {
+	repo *repository.WebhookSubscriberRepository
+}
+
+// NewWebhookService creates a new WebhookService
+func NewWebhookService(repo *repository.WebhookSubscriberRepository) *WebhookService {
+	return &WebhookService{
+		repo: repo,
+	}
+}
+
+// GetSubscribers gets all webhook subscribers
+func (s *WebhookService) GetSubscribers() ([]models.WebhookSubscriber, error) {
+	return s.repo.GetSubscribers()
+}
+
+// CreateSubscriber registers a webhook URL and issues its first secret
+func (s *WebhookService) CreateSubscriber(req *models.CreateWebhookSubscriberRequest) (*models.WebhookSubscriberSecret, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	subscriber := &models.WebhookSubscriber{
+		ID:        uuid.New().String(),
+		URL:       req.URL,
+		Secret:    secret,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.CreateSubscriber(subscriber); err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookSubscriberSecret{WebhookSubscriber: *subscriber, Secret: secret}, nil
+}
+
+// RotateSecret issues a new secret for a subscriber. The previous secret keeps signing
+// deliveries alongside the new one until the grace period ends, so receivers can switch
+// over without dropping deliveries.
+func (s *WebhookService) RotateSecret(id string, req *models.RotateWebhookSecretRequest) (*models.WebhookSubscriberSecret, error) {
+	grace := defaultGracePeriod
+	if req.GracePeriod != "" {
+		d, err := time.ParseDuration(req.GracePeriod)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidGracePeriod, req.GracePeriod)
+		}
+		grace = d
+	}
+
+	subscriber, err := s.repo.GetSubscriberByID(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSubscriberNotFound
+		}
+		return nil, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(grace)
+	previous := subscriber.Secret
+	subscriber.PreviousSecret = &previous
+	subscriber.PreviousSecretExpiresAt = &expiresAt
+	subscriber.Secret = secret
+	subscriber.UpdatedAt = now
+
+	if err := s.repo.UpdateSecrets(subscriber); err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookSubscriberSecret{WebhookSubscriber: *subscriber, Secret: secret}, nil
+}
+
+// DeleteSubscriber removes a webhook subscriber
+func (s *WebhookService) DeleteSubscriber(id string) error {
+	return s.repo.DeleteSubscriber(id)
+}
+
+// WebhookSecrets returns the active signing secrets for a webhook URL
+func (s *WebhookService) WebhookSecrets(url string) ([]string, error) {
+	subscriber, err := s.repo.GetSubscriberByURL(url)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", channel.ErrNoSubscriber, url)
+		}
+		return nil, err
+	}
+	return subscriber.ActiveSecrets(time.Now()), nil
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,230 @@
+package templating
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+)
+
+var (
+	// ErrInvalidTemplate is returned when a template cannot be parsed or references undeclared variables
+	ErrInvalidTemplate = errors.New("invalid template")
+	// ErrMissingVariables is returned when render data lacks variables the template declares
+	ErrMissingVariables = errors.New("missing template variables")
+)
+
+// Validate parses every part of the template and checks that all top-level variables
+// it references are declared in its variable list
+func Validate(t *models.Template) error {
+	declared := make(map[string]bool, len(t.Variables))
+	for _, v := range t.Variables {
+		declared[v] = true
+	}
+
+	var undeclared []string
+	for part, text := range parts(t) {
+		refs, err := References(text)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidTemplate, part, err)
+		}
+		for _, ref := range refs {
+			if !declared[ref] {
+				undeclared = append(undeclared, ref)
+			}
+		}
+	}
+
+	if len(undeclared) > 0 {
+		return fmt.Errorf("%w: undeclared variables %s", ErrInvalidTemplate, strings.Join(dedupe(undeclared), ", "))
+	}
+	return nil
+}
+
+// Render renders the template with data. The subject and text body use text/template
+// and the HTML body uses html/template.
+func Render(t *models.Template, data map[string]interface{}) (*models.RenderedTemplate, error) {
+	var missing []string
+	for _, v := range t.Variables {
+		if _, ok := data[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingVariables, strings.Join(missing, ", "))
+	}
+
+	subject, err := renderText("subject", t.Subject, data)
+	if err != nil {
+		return nil, err
+	}
+
+	textBody, err := renderText("text_body", t.TextBody, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var htmlBody string
+	if t.HTMLBody != "" {
+		tmpl, err := htmltemplate.New("html_body").Option("missingkey=error").Parse(t.HTMLBody)
+		if err != nil {
+			return nil, fmt.Errorf("%w: html_body: %v", ErrInvalidTemplate, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render html_body: %w", err)
+		}
+		htmlBody = buf.String()
+	}
+
+	return &models.RenderedTemplate{
+		Name:     t.Name,
+		Locale:   t.Locale,
+		Version:  t.Version,
+		Subject:  strings.TrimSpace(subject),
+		TextBody: textBody,
+		HTMLBody: htmlBody,
+	}, nil
+}
+
+// LocaleChain returns the locales to try for the requested locale, most specific first,
+// ending with the fallback locale. For example "de-AT" with fallback "en" yields
+// ["de-AT", "de", "en"].
+func LocaleChain(locale, fallback string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+	add := func(l string) {
+		if l != "" && !seen[l] {
+			seen[l] = true
+			chain = append(chain, l)
+		}
+	}
+
+	locale = strings.ReplaceAll(locale, "_", "-")
+	for l := locale; l != ""; {
+		add(l)
+		i := strings.LastIndex(l, "-")
+		if i < 0 {
+			break
+		}
+		l = l[:i]
+	}
+	add(fallback)
+
+	return chain
+}
+
+// References returns the top-level variables referenced by a template text
+func References(text string) ([]string, error) {
+	// Function names are resolved at render time, so skip the parser's function check
+	tree := parse.New("template")
+	tree.Mode = parse.SkipFuncCheck
+	trees := make(map[string]*parse.Tree)
+	if _, err := tree.Parse(text, "", "", trees); err != nil {
+		return nil, err
+	}
+
+	refs := make(map[string]bool)
+	for _, tree := range trees {
+		if tree.Root != nil {
+			collect(tree.Root, refs, true)
+		}
+	}
+
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// collect walks a parse tree and records fields accessed on the top-level data. Bodies
+// of range and with blocks rebind dot, so only $-rooted fields count inside them.
+func collect(node parse.Node, refs map[string]bool, top bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collect(child, refs, top)
+		}
+	case *parse.ActionNode:
+		collect(n.Pipe, refs, top)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collect(cmd, refs, top)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collect(arg, refs, top)
+		}
+	case *parse.FieldNode:
+		if top {
+			refs[n.Ident[0]] = true
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			refs[n.Ident[1]] = true
+		}
+	case *parse.ChainNode:
+		collect(n.Node, refs, top)
+	case *parse.IfNode:
+		collect(n.Pipe, refs, top)
+		collect(n.List, refs, top)
+		collect(n.ElseList, refs, top)
+	case *parse.RangeNode:
+		collect(n.Pipe, refs, top)
+		collect(n.List, refs, false)
+		collect(n.ElseList, refs, top)
+	case *parse.WithNode:
+		collect(n.Pipe, refs, top)
+		collect(n.List, refs, false)
+		collect(n.ElseList, refs, top)
+	case *parse.TemplateNode:
+		collect(n.Pipe, refs, top)
+	}
+}
+
+func renderText(name, text string, data map[string]interface{}) (string, error) {
+	tmpl, err := texttemplate.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrInvalidTemplate, name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+func parts(t *models.Template) map[string]string {
+	return map[string]string{
+		"subject":   t.Subject,
+		"text_body": t.TextBody,
+		"html_body": t.HTMLBody,
+	}
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := values[:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
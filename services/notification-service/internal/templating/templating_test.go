@@ -0,0 +1,131 @@
+package templating
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+)
+
+func TestReferences(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"plain text", "Welcome aboard", []string{}},
+		{"fields", "Hi {{.name}}, see {{.link}}", []string{"link", "name"}},
+		{"nested field", "{{.user.name}}", []string{"user"}},
+		{"pipeline", `{{.name | printf "%q"}}`, []string{"name"}},
+		{"if", "{{if .admin}}{{.role}}{{else}}{{.fallback}}{{end}}", []string{"admin", "fallback", "role"}},
+		{"range rebinds dot", "{{range .items}}{{.sku}}{{end}}", []string{"items"}},
+		{"range else keeps dot", "{{range .items}}{{.sku}}{{else}}{{.empty}}{{end}}", []string{"empty", "items"}},
+		{"with rebinds dot", "{{with .user}}{{.name}}{{end}}", []string{"user"}},
+		{"root variable inside range", "{{range .items}}{{$.currency}}{{end}}", []string{"currency", "items"}},
+		{"root variable inside with", "{{with .user}}{{$.link}}{{end}}", []string{"link", "user"}},
+		{"local variable", "{{$n := .name}}{{$n.first}}", []string{"name"}},
+		{"unknown function", "{{upper .name}}", []string{"name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := References(tt.text)
+			if err != nil {
+				t.Fatalf("References(%q): %v", tt.text, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("References(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := References("{{.name"); err == nil {
+		t.Error("References with an unclosed action: got nil error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template models.Template
+		wantErr  bool
+	}{
+		{
+			name:     "all declared",
+			template: models.Template{Subject: "Hi {{.name}}", TextBody: "{{.link}}", HTMLBody: "<a href=\"{{.link}}\">go</a>", Variables: []string{"name", "link"}},
+		},
+		{
+			name:     "undeclared in subject",
+			template: models.Template{Subject: "Hi {{.name}}", TextBody: "body", Variables: []string{"link"}},
+			wantErr:  true,
+		},
+		{
+			name:     "undeclared in html body",
+			template: models.Template{Subject: "Hi", TextBody: "body", HTMLBody: "{{.link}}"},
+			wantErr:  true,
+		},
+		{
+			name:     "range body fields need no declaration",
+			template: models.Template{Subject: "Order", TextBody: "{{range .items}}{{.sku}} {{$.currency}}{{end}}", Variables: []string{"items", "currency"}},
+		},
+		{
+			name:     "root variable inside range is checked",
+			template: models.Template{Subject: "Order", TextBody: "{{range .items}}{{$.currency}}{{end}}", Variables: []string{"items"}},
+			wantErr:  true,
+		},
+		{
+			name:     "parse error",
+			template: models.Template{Subject: "{{if .name}}", TextBody: "body", Variables: []string{"name"}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.template)
+			if tt.wantErr && !errors.Is(err, ErrInvalidTemplate) {
+				t.Errorf("Validate: got %v, want ErrInvalidTemplate", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestRenderMissingVariables(t *testing.T) {
+	tmpl := &models.Template{Subject: "Hi {{.name}}", TextBody: "{{.link}}", Variables: []string{"name", "link"}}
+
+	if _, err := Render(tmpl, map[string]interface{}{"name": "Ada"}); !errors.Is(err, ErrMissingVariables) {
+		t.Errorf("Render without link: got %v, want ErrMissingVariables", err)
+	}
+
+	rendered, err := Render(tmpl, map[string]interface{}{"name": "Ada", "link": "https://example.com"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if rendered.Subject != "Hi Ada" || rendered.TextBody != "https://example.com" {
+		t.Errorf("Render = %+v", rendered)
+	}
+}
+
+func TestLocaleChain(t *testing.T) {
+	tests := []struct {
+		locale   string
+		fallback string
+		want     []string
+	}{
+		{"de-AT", "en", []string{"de-AT", "de", "en"}},
+		{"de_AT", "en", []string{"de-AT", "de", "en"}},
+		{"zh-Hant-TW", "en", []string{"zh-Hant-TW", "zh-Hant", "zh", "en"}},
+		{"en-GB", "en", []string{"en-GB", "en"}},
+		{"en", "en", []string{"en"}},
+		{"", "en", []string{"en"}},
+		{"fr", "", []string{"fr"}},
+	}
+	for _, tt := range tests {
+		got := LocaleChain(tt.locale, tt.fallback)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LocaleChain(%q, %q) = %v, want %v", tt.locale, tt.fallback, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/notification-service/internal/service"
+)
+
+// DigestScheduler periodically queues the digests that have come due
+type DigestScheduler struct {
+	digests  *service.DigestService
+	interval time.Duration
+	logger   *logrus.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDigestScheduler creates a new DigestScheduler
+func NewDigestScheduler(digests *service.DigestService, interval time.Duration, logger *logrus.Logger) *DigestScheduler {
+	return &DigestScheduler{
+		digests:  digests,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Start launches the scheduler
+func (s *DigestScheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx)
+	}()
+}
+
+// Stop stops the scheduler and waits for a running pass to finish
+func (s *DigestScheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *DigestScheduler) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		sent, err := s.digests.SendDue()
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to send digests")
+		}
+		if sent > 0 {
+			s.logger.WithField("digests", sent).Info("Queued digests")
+		}
+	}
+}
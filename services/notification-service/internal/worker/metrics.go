@@ -0,0 +1,33 @@
+package worker
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	queueDepth = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "notification_queue_depth",
+			Help: "Number of notifications in each queue status",
+		},
+		[]string{"status"},
+	)
+
+	deliveryAttemptsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notification_delivery_attempts_total",
+			Help: "Total number of notification delivery attempts by outcome",
+		},
+		[]string{"channel", "outcome"},
+	)
+
+	deliveryLatency = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "notification_delivery_latency_seconds",
+			Help:    "Time from enqueueing a notification to delivering it in seconds",
+			Buckets: prometheus.ExponentialBuckets(0.1, 4, 9),
+		},
+		[]string{"channel"},
+	)
+)
@@ -0,0 +1,256 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/notification-service/internal/channel"
+	"github.com/yourusername/go-microservices/notification-service/internal/config"
+	"github.com/yourusername/go-microservices/notification-service/internal/models"
+	"github.com/yourusername/go-microservices/notification-service/internal/repository"
+	"github.com/yourusername/go-microservices/notification-service/internal/service"
+)
+
+const (
+	// sendTimeout bounds a single delivery attempt
+	sendTimeout = 30 * time.Second
+	// lease is how long a claimed notification stays reserved for the claiming worker
+	lease = sendTimeout + 30*time.Second
+	// depthInterval is how often the queue depth gauge is refreshed
+	depthInterval = 15 * time.Second
+)
+
+// defaultRetryPolicy applies to channels without a configured retry policy
+var defaultRetryPolicy = config.RetryPolicy{MaxAttempts: 3, BaseDelay: 30 * time.Second, MaxDelay: 10 * time.Minute}
+
+// Pool is a fixed-size pool of workers delivering queued notifications
+type Pool struct {
+	repo         *repository.NotificationRepository
+	preferences  *service.PreferenceService
+	channels     *channel.Registry
+	policies     map[string]config.RetryPolicy
+	logger       *logrus.Logger
+	size         int
+	pollInterval time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPool creates a new Pool
+func NewPool(repo *repository.NotificationRepository, preferences *service.PreferenceService, channels *channel.Registry, policies map[string]config.RetryPolicy, size int, pollInterval time.Duration, logger *logrus.Logger) *Pool {
+	return &Pool{
+		repo:         repo,
+		preferences:  preferences,
+		channels:     channels,
+		policies:     policies,
+		logger:       logger,
+		size:         size,
+		pollInterval: pollInterval,
+	}
+}
+
+// Start launches the workers
+func (p *Pool) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	for i := 0; i < p.size; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.run(ctx)
+		}()
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.reportDepth(ctx)
+	}()
+}
+
+// Stop stops claiming new notifications and waits for in-flight deliveries to finish
+func (p *Pool) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+}
+
+func (p *Pool) run(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		notifications, err := p.repo.ClaimNotifications(1, lease)
+		if err != nil {
+			p.logger.WithError(err).Error("Failed to claim notifications")
+		}
+
+		if len(notifications) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(p.pollInterval):
+			}
+			continue
+		}
+
+		for i := range notifications {
+			p.process(&notifications[i])
+		}
+	}
+}
+
+// process delivers a claimed notification and records the outcome. In-flight sends are
+// not tied to the pool's context so that shutdown lets them finish.
+func (p *Pool) process(n *models.Notification) {
+	logger := p.logger.WithFields(logrus.Fields{
+		"notification_id": n.ID,
+		"channel":         n.Channel,
+		"attempt":         n.Attempts,
+	})
+
+	// Preferences are checked at send time so that changes apply to already queued notifications
+	decision, err := p.preferences.Check(n, time.Now())
+	if err != nil {
+		logger.WithError(err).Error("Failed to check notification preferences")
+		decision = &service.Decision{Action: service.DecisionHold, Reason: "preferences unavailable", Until: time.Now().Add(p.pollInterval)}
+	}
+	switch decision.Action {
+	case service.DecisionSuppress:
+		logger.WithField("reason", decision.Reason).Info("Notification suppressed")
+		if err := p.repo.MarkSuppressed(n, decision.Reason); err != nil {
+			recordFailed(logger, err, "Failed to record suppressed notification")
+		}
+		deliveryAttemptsTotal.WithLabelValues(n.Channel, "suppressed").Inc()
+		return
+	case service.DecisionBatch:
+		if err := p.repo.MarkBatched(n, decision.Until); err != nil {
+			recordFailed(logger, err, "Failed to batch notification")
+		}
+		deliveryAttemptsTotal.WithLabelValues(n.Channel, "batched").Inc()
+		return
+	case service.DecisionHold:
+		if err := p.repo.MarkHeld(n, decision.Reason, decision.Until); err != nil {
+			recordFailed(logger, err, "Failed to hold notification")
+		}
+		return
+	}
+
+	ch, err := p.channels.Get(n.Channel)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err = ch.Send(ctx, messageFor(n))
+		cancel()
+	} else {
+		err = &channel.PermanentError{Err: err}
+	}
+
+	if err == nil {
+		now := time.Now()
+		if err := p.repo.MarkSent(n, now); err != nil {
+			recordFailed(logger, err, "Failed to record notification delivery")
+		}
+		deliveryAttemptsTotal.WithLabelValues(n.Channel, "sent").Inc()
+		deliveryLatency.WithLabelValues(n.Channel).Observe(now.Sub(n.CreatedAt).Seconds())
+		return
+	}
+
+	policy := p.policy(n.Channel)
+	if channel.IsPermanent(err) || n.Attempts >= policy.MaxAttempts {
+		logger.WithError(err).Error("Notification moved to dead letters")
+		if err := p.repo.MarkDead(n, err.Error()); err != nil {
+			recordFailed(logger, err, "Failed to dead-letter notification")
+		}
+		deliveryAttemptsTotal.WithLabelValues(n.Channel, "dead").Inc()
+		return
+	}
+
+	next := time.Now().Add(Backoff(policy, n.Attempts))
+	logger.WithError(err).WithField("next_attempt_at", next).Warn("Notification delivery failed, retrying")
+	if err := p.repo.MarkRetry(n, err.Error(), next); err != nil {
+		recordFailed(logger, err, "Failed to schedule notification retry")
+	}
+	deliveryAttemptsTotal.WithLabelValues(n.Channel, "retry").Inc()
+}
+
+// recordFailed logs a failure to record the outcome of a claim. Losing the lease means the
+// claim outlived it and another worker has taken the notification over, which is expected
+// after a slow send and not an error.
+func recordFailed(logger *logrus.Entry, err error, msg string) {
+	if errors.Is(err, repository.ErrLeaseLost) {
+		logger.Warn("Notification lease expired before the outcome was recorded")
+		return
+	}
+	logger.WithError(err).Error(msg)
+}
+
+func (p *Pool) policy(name string) config.RetryPolicy {
+	if policy, ok := p.policies[name]; ok {
+		return policy
+	}
+	return defaultRetryPolicy
+}
+
+// reportDepth refreshes the queue depth gauge and sweeps expired scheduled leases
+func (p *Pool) reportDepth(ctx context.Context) {
+	ticker := time.NewTicker(depthInterval)
+	defer ticker.Stop()
+
+	for {
+		if expired, err := p.repo.ExpireScheduledLeases(); err != nil {
+			p.logger.WithError(err).Error("Failed to expire scheduled notification leases")
+		} else if expired > 0 {
+			p.logger.WithField("count", expired).Warn("Scheduled notifications dead-lettered after their lease expired")
+		}
+
+		counts, err := p.repo.CountByStatus(models.StatusScheduled, models.StatusQueued, models.StatusProcessing, models.StatusRetrying, models.StatusHeld, models.StatusBatched, models.StatusDead)
+		if err != nil {
+			p.logger.WithError(err).Error("Failed to count queued notifications")
+		}
+		for status, count := range counts {
+			queueDepth.WithLabelValues(status).Set(float64(count))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Backoff returns the delay before the next attempt after the given number of failed
+// attempts: the base delay doubled per attempt, capped at the maximum, with up to 10% jitter
+func Backoff(policy config.RetryPolicy, attempts int) time.Duration {
+	delay := policy.BaseDelay
+	for i := 1; i < attempts && delay < policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	if jitter := int64(delay / 10); jitter > 0 {
+		delay += time.Duration(rand.Int63n(jitter))
+	}
+	return delay
+}
+
+func messageFor(n *models.Notification) *channel.Message {
+	return &channel.Message{
+		ID:        n.ID,
+		Recipient: n.Recipient,
+		Template:  n.Template,
+		Subject:   n.Subject,
+		Body:      n.TextBody,
+		HTMLBody:  n.HTMLBody,
+		Data:      n.Data,
+	}
+}
@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/go-microservices/notification-service/internal/config"
+)
+
+func TestBackoff(t *testing.T) {
+	policy := config.RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{5, 16 * time.Second},
+		{6, 30 * time.Second},
+		{50, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		// Jitter adds up to 10% on top of the schedule
+		for i := 0; i < 20; i++ {
+			got := Backoff(policy, tt.attempts)
+			if got < tt.want || got >= tt.want+tt.want/10 {
+				t.Fatalf("Backoff after %d attempts = %v, want %v plus under 10%%", tt.attempts, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestBackoffWithoutJitter(t *testing.T) {
+	// Delays too short to have a tenth are returned as they are
+	policy := config.RetryPolicy{BaseDelay: 5 * time.Nanosecond, MaxDelay: 8 * time.Nanosecond}
+	if got := Backoff(policy, 1); got != 5*time.Nanosecond {
+		t.Errorf("Backoff after 1 attempt = %v, want 5ns", got)
+	}
+	if got := Backoff(policy, 3); got != 8*time.Nanosecond {
+		t.Errorf("Backoff after 3 attempts = %v, want 8ns", got)
+	}
+}
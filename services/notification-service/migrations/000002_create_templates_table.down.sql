@@ -0,0 +1,5 @@
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS template_version,
+    DROP COLUMN IF EXISTS locale;
+
+DROP TABLE IF EXISTS templates;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    locale VARCHAR(35) NOT NULL,
+    version INTEGER NOT NULL,
+    subject TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    html_body TEXT NOT NULL DEFAULT '',
+    variables TEXT[] NOT NULL DEFAULT '{}',
+    sample_data JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (name, locale, version)
+);
+
+CREATE INDEX idx_templates_name_locale ON templates(name, locale);
+
+ALTER TABLE notifications
+    ADD COLUMN locale VARCHAR(35) NOT NULL DEFAULT '',
+    ADD COLUMN template_version INTEGER;
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_notifications_queue;
+
+UPDATE notifications SET status = 'pending' WHERE status IN ('queued', 'processing', 'retrying');
+UPDATE notifications SET status = 'failed' WHERE status = 'dead';
+
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS locked_until,
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS attempts,
+    DROP COLUMN IF EXISTS html_body,
+    DROP COLUMN IF EXISTS text_body,
+    DROP COLUMN IF EXISTS subject;
@@ -0,0 +1,12 @@
+ALTER TABLE notifications
+    ADD COLUMN subject TEXT NOT NULL DEFAULT '',
+    ADD COLUMN text_body TEXT NOT NULL DEFAULT '',
+    ADD COLUMN html_body TEXT NOT NULL DEFAULT '',
+    ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
+
+UPDATE notifications SET status = 'queued' WHERE status = 'pending';
+UPDATE notifications SET status = 'dead' WHERE status = 'failed';
+
+CREATE INDEX idx_notifications_queue ON notifications(status, next_attempt_at);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS webhook_subscribers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    url VARCHAR(2048) UNIQUE NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    previous_secret VARCHAR(255),
+    previous_secret_expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_notifications_user_id;
+
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS status_reason,
+    DROP COLUMN IF EXISTS critical,
+    DROP COLUMN IF EXISTS category,
+    DROP COLUMN IF EXISTS user_id;
+
+DROP TABLE IF EXISTS notification_preferences;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id VARCHAR(255) PRIMARY KEY,
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    quiet_hours_start VARCHAR(5),
+    quiet_hours_end VARCHAR(5),
+    disabled_channels TEXT[] NOT NULL DEFAULT '{}',
+    disabled_categories TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE notifications
+    ADD COLUMN user_id VARCHAR(255),
+    ADD COLUMN category VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN critical BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN status_reason TEXT;
+
+CREATE INDEX idx_notifications_user_id ON notifications(user_id);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS inbox_messages_notify ON inbox_messages;
+DROP FUNCTION IF EXISTS notify_inbox_message();
+DROP TABLE IF EXISTS inbox_messages;
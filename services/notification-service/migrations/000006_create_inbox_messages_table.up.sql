@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS inbox_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id VARCHAR(255) NOT NULL,
+    notification_id UUID UNIQUE REFERENCES notifications(id) ON DELETE SET NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_inbox_messages_user_id_created_at ON inbox_messages(user_id, created_at DESC);
+CREATE INDEX idx_inbox_messages_unread ON inbox_messages(user_id) WHERE read_at IS NULL;
+
+-- Every replica listens on this channel so that live streams receive messages
+-- delivered by workers on other replicas
+CREATE OR REPLACE FUNCTION notify_inbox_message() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('inbox_messages', json_build_object('id', NEW.id, 'user_id', NEW.user_id)::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER inbox_messages_notify
+    AFTER INSERT ON inbox_messages
+    FOR EACH ROW EXECUTE FUNCTION notify_inbox_message();
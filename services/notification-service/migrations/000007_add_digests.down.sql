@@ -0,0 +1,11 @@
+DELETE FROM templates WHERE name = 'digest' AND version = 1;
+
+DROP INDEX IF EXISTS idx_notifications_batched;
+DROP INDEX IF EXISTS idx_notifications_dedup_key;
+
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS digest_id,
+    DROP COLUMN IF EXISTS dedup_key;
+
+ALTER TABLE notification_preferences
+    DROP COLUMN IF EXISTS digest;
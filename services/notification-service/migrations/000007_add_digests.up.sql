@@ -0,0 +1,24 @@
+ALTER TABLE notification_preferences
+    ADD COLUMN digest VARCHAR(16) NOT NULL DEFAULT 'immediate';
+
+ALTER TABLE notifications
+    ADD COLUMN dedup_key VARCHAR(255),
+    ADD COLUMN digest_id UUID REFERENCES notifications(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX idx_notifications_dedup_key ON notifications(channel, recipient, dedup_key)
+    WHERE dedup_key IS NOT NULL;
+CREATE INDEX idx_notifications_batched ON notifications(user_id, channel, recipient)
+    WHERE status = 'batched';
+
+INSERT INTO templates (name, locale, version, subject, text_body, html_body, variables, sample_data)
+VALUES (
+    'digest',
+    'en',
+    1,
+    'You have {{.count}} new notifications',
+    E'{{range .items}}{{.subject}}\n\n{{.body}}\n\n{{end}}',
+    E'{{range .items}}<h2>{{.subject}}</h2>\n<p>{{.body}}</p>\n{{end}}',
+    ARRAY['items', 'count'],
+    '{"count": 2, "items": [{"subject": "Order shipped", "body": "Your order is on its way."}, {"subject": "New comment", "body": "Someone replied to your post."}]}'
+)
+ON CONFLICT (name, locale, version) DO NOTHING;
@@ -0,0 +1,2 @@
+ALTER TABLE notifications
+    ADD COLUMN send_at TIMESTAMP WITH TIME ZONE;
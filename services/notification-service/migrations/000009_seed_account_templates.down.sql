@@ -0,0 +1 @@
+DELETE FROM templates WHERE name IN ('welcome', 'email_changed') AND version = 1;
@@ -0,0 +1,23 @@
+INSERT INTO templates (name, locale, version, subject, text_body, html_body, variables, sample_data)
+VALUES
+(
+    'welcome',
+    'en',
+    1,
+    'Welcome, {{.name}}!',
+    E'Hi {{.name}},\n\nThanks for signing up. We are glad to have you.\n',
+    E'<p>Hi {{.name}},</p>\n<p>Thanks for signing up. We are glad to have you.</p>\n',
+    ARRAY['name'],
+    '{"name": "Ada"}'
+),
+(
+    'email_changed',
+    'en',
+    1,
+    'Your email address was changed',
+    E'Hi {{.name}},\n\nThe email address of your account was changed from {{.old_email}} to {{.new_email}}.\nIf you did not make this change, contact support right away.\n',
+    E'<p>Hi {{.name}},</p>\n<p>The email address of your account was changed from {{.old_email}} to {{.new_email}}.</p>\n<p>If you did not make this change, contact support right away.</p>\n',
+    ARRAY['name', 'old_email', 'new_email'],
+    '{"name": "Ada", "old_email": "ada@example.com", "new_email": "ada@example.org"}'
+)
+ON CONFLICT (name, locale, version) DO NOTHING;
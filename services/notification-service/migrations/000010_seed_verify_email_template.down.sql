@@ -0,0 +1 @@
+DELETE FROM templates WHERE name = 'verify_email' AND version = 1;
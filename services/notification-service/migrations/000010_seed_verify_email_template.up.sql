@@ -0,0 +1,12 @@
+INSERT INTO templates (name, locale, version, subject, text_body, html_body, variables, sample_data)
+VALUES (
+    'verify_email',
+    'en',
+    1,
+    'Confirm your email address',
+    E'Hi {{.name}},\n\nConfirm your email address by opening this link:\n{{.link}}\n\nIf you did not request this, you can ignore this message.\n',
+    E'<p>Hi {{.name}},</p>\n<p><a href="{{.link}}">Confirm your email address</a></p>\n<p>If you did not request this, you can ignore this message.</p>\n',
+    ARRAY['name', 'link'],
+    '{"name": "Ada", "link": "http://localhost:8080/verify-email?token=example"}'
+)
+ON CONFLICT (name, locale, version) DO NOTHING;
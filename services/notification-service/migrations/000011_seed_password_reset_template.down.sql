@@ -0,0 +1 @@
+DELETE FROM templates WHERE name = 'password_reset' AND version = 1;
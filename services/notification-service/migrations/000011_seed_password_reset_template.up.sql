@@ -0,0 +1,12 @@
+INSERT INTO templates (name, locale, version, subject, text_body, html_body, variables, sample_data)
+VALUES (
+    'password_reset',
+    'en',
+    1,
+    'Reset your password',
+    E'Hi {{.name}},\n\nSet a new password by opening this link:\n{{.link}}\n\nThe link expires soon and can be used once. If you did not request this, you can ignore this message; your password has not changed.\n',
+    E'<p>Hi {{.name}},</p>\n<p><a href="{{.link}}">Set a new password</a></p>\n<p>The link expires soon and can be used once. If you did not request this, you can ignore this message; your password has not changed.</p>\n',
+    ARRAY['name', 'link'],
+    '{"name": "Ada", "link": "http://localhost:8080/reset-password?token=example"}'
+)
+ON CONFLICT (name, locale, version) DO NOTHING;
@@ -0,0 +1,3 @@
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS redacted_at,
+    DROP COLUMN IF EXISTS sensitive;
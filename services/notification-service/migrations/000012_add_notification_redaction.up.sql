@@ -0,0 +1,10 @@
+ALTER TABLE notifications
+    ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN redacted_at TIMESTAMP WITH TIME ZONE;
+
+-- Verification and password reset links carry account tokens
+UPDATE notifications SET sensitive = TRUE WHERE template IN ('verify_email', 'password_reset');
+
+UPDATE notifications
+SET data = '{}', text_body = '', html_body = '', redacted_at = NOW()
+WHERE sensitive AND status IN ('sent', 'suppressed', 'dead', 'cancelled');
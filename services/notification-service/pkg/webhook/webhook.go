@@ -0,0 +1,143 @@
+// Package webhook signs and verifies notification-service webhook deliveries.
+//
+// Every delivery carries two headers:
+//
+//	X-Notification-Timestamp: 1700000000
+//	X-Notification-Signature: v1=5257a869...,v1=6ffbb59b...
+//
+// Each v1 entry is the hex-encoded HMAC-SHA256 of "<timestamp>.<body>" under one of the
+// subscriber's active secrets. While a secret is being rotated both the new and the
+// previous secret sign the payload, so receivers holding either one can verify it.
+// Receivers should reject deliveries whose timestamp is outside a small tolerance to
+// prevent captured requests from being replayed.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TimestampHeader carries the Unix time at which the delivery was signed
+	TimestampHeader = "X-Notification-Timestamp"
+	// SignatureHeader carries one or more signatures of the delivery
+	SignatureHeader = "X-Notification-Signature"
+	// DefaultTolerance is the maximum accepted age of a delivery
+	DefaultTolerance = 5 * time.Minute
+
+	signatureVersion = "v1"
+)
+
+var (
+	// ErrMissingHeaders is returned when the timestamp or signature header is absent
+	ErrMissingHeaders = errors.New("webhook: missing signature headers")
+	// ErrInvalidTimestamp is returned when the timestamp header is not a Unix time
+	ErrInvalidTimestamp = errors.New("webhook: invalid timestamp")
+	// ErrStaleTimestamp is returned when the timestamp is outside the accepted tolerance
+	ErrStaleTimestamp = errors.New("webhook: timestamp outside tolerance")
+	// ErrInvalidSignature is returned when no signature matches any of the secrets
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+)
+
+// Sign returns the hex-encoded signature of body at timestamp under secret
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeaderValue returns the signature header value for body signed with every secret
+func SignatureHeaderValue(secrets []string, timestamp int64, body []byte) string {
+	signatures := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		signatures = append(signatures, signatureVersion+"="+Sign(secret, timestamp, body))
+	}
+	return strings.Join(signatures, ",")
+}
+
+// SignRequest sets the timestamp and signature headers on a request carrying body
+func SignRequest(req *http.Request, secrets []string, body []byte, now time.Time) {
+	timestamp := now.Unix()
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, SignatureHeaderValue(secrets, timestamp, body))
+}
+
+// Verifier checks signed deliveries against a subscriber's secrets
+type Verifier struct {
+	// Secrets are the subscriber's currently valid secrets. During rotation this holds
+	// both the new and the previous secret.
+	Secrets []string
+	// Tolerance is the maximum accepted clock difference; DefaultTolerance when zero
+	Tolerance time.Duration
+	// Now returns the current time; time.Now when nil
+	Now func() time.Time
+}
+
+// Verify checks the timestamp and signature headers against body
+func (v *Verifier) Verify(timestampHeader, signatureHeader string, body []byte) error {
+	if timestampHeader == "" || signatureHeader == "" {
+		return ErrMissingHeaders
+	}
+
+	timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+
+	tolerance := v.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultTolerance
+	}
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+
+	age := now().Sub(time.Unix(timestamp, 0))
+	if age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: signed %s ago", ErrStaleTimestamp, age.Round(time.Second))
+	}
+
+	for _, part := range strings.Split(signatureHeader, ",") {
+		version, signature, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || version != signatureVersion {
+			continue
+		}
+		got, err := hex.DecodeString(signature)
+		if err != nil {
+			continue
+		}
+		for _, secret := range v.Secrets {
+			want, _ := hex.DecodeString(Sign(secret, timestamp, body))
+			if hmac.Equal(got, want) {
+				return nil
+			}
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// VerifyRequest reads the request body and verifies it. The body is returned so the
+// caller can decode it after a successful check.
+func (v *Verifier) VerifyRequest(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: failed to read body: %w", err)
+	}
+
+	if err := v.Verify(r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
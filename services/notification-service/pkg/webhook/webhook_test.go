@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var (
+	body = []byte(`{"id":"123","recipient":"https://example.com/hook"}`)
+	now  = time.Unix(1700000000, 0)
+)
+
+func fixedNow() time.Time { return now }
+
+func TestVerify(t *testing.T) {
+	ts := now.Unix()
+
+	tests := []struct {
+		name      string
+		secrets   []string
+		timestamp string
+		signature string
+		body      []byte
+		want      error
+	}{
+		{
+			name:      "valid signature",
+			secrets:   []string{"current"},
+			timestamp: strconv.FormatInt(ts, 10),
+			signature: SignatureHeaderValue([]string{"current"}, ts, body),
+			body:      body,
+		},
+		{
+			name:      "signed with previous secret during rotation",
+			secrets:   []string{"current", "previous"},
+			timestamp: strconv.FormatInt(ts, 10),
+			signature: SignatureHeaderValue([]string{"previous"}, ts, body),
+			body:      body,
+		},
+		{
+			name:      "receiver still on previous secret",
+			secrets:   []string{"previous"},
+			timestamp: strconv.FormatInt(ts, 10),
+			signature: SignatureHeaderValue([]string{"current", "previous"}, ts, body),
+			body:      body,
+		},
+		{
+			name:      "unknown secret",
+			secrets:   []string{"current"},
+			timestamp: strconv.FormatInt(ts, 10),
+			signature: SignatureHeaderValue([]string{"other"}, ts, body),
+			body:      body,
+			want:      ErrInvalidSignature,
+		},
+		{
+			name:      "tampered body",
+			secrets:   []string{"current"},
+			timestamp: strconv.FormatInt(ts, 10),
+			signature: SignatureHeaderValue([]string{"current"}, ts, body),
+			body:      []byte(`{"id":"456"}`),
+			want:      ErrInvalidSignature,
+		},
+		{
+			name:      "timestamp swapped after signing",
+			secrets:   []string{"current"},
+			timestamp: strconv.FormatInt(ts-10, 10),
+			signature: SignatureHeaderValue([]string{"current"}, ts, body),
+			body:      body,
+			want:      ErrInvalidSignature,
+		},
+		{
+			name:      "stale timestamp",
+			secrets:   []string{"current"},
+			timestamp: strconv.FormatInt(ts-int64(DefaultTolerance/time.Second)-1, 10),
+			signature: SignatureHeaderValue([]string{"current"}, ts-int64(DefaultTolerance/time.Second)-1, body),
+			body:      body,
+			want:      ErrStaleTimestamp,
+		},
+		{
+			name:      "timestamp too far in the future",
+			secrets:   []string{"current"},
+			timestamp: strconv.FormatInt(ts+int64(DefaultTolerance/time.Second)+1, 10),
+			signature: SignatureHeaderValue([]string{"current"}, ts+int64(DefaultTolerance/time.Second)+1, body),
+			body:      body,
+			want:      ErrStaleTimestamp,
+		},
+		{
+			name:      "malformed timestamp",
+			secrets:   []string{"current"},
+			timestamp: "yesterday",
+			signature: SignatureHeaderValue([]string{"current"}, ts, body),
+			body:      body,
+			want:      ErrInvalidTimestamp,
+		},
+		{
+			name:      "missing signature",
+			secrets:   []string{"current"},
+			timestamp: strconv.FormatInt(ts, 10),
+			body:      body,
+			want:      ErrMissingHeaders,
+		},
+		{
+			name:      "unsupported signature version",
+			secrets:   []string{"current"},
+			timestamp: strconv.FormatInt(ts, 10),
+			signature: "v0=" + Sign("current", ts, body),
+			body:      body,
+			want:      ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Verifier{Secrets: tt.secrets, Now: fixedNow}
+			err := v.Verify(tt.timestamp, tt.signature, tt.body)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyCustomTolerance(t *testing.T) {
+	ts := now.Add(-time.Minute).Unix()
+	signature := SignatureHeaderValue([]string{"current"}, ts, body)
+
+	v := &Verifier{Secrets: []string{"current"}, Tolerance: 30 * time.Second, Now: fixedNow}
+	if err := v.Verify(strconv.FormatInt(ts, 10), signature, body); !errors.Is(err, ErrStaleTimestamp) {
+		t.Fatalf("Verify() error = %v, want %v", err, ErrStaleTimestamp)
+	}
+
+	v.Tolerance = 2 * time.Minute
+	if err := v.Verify(strconv.FormatInt(ts, 10), signature, body); err != nil {
+		t.Fatalf("Verify() error = %v, want nil", err)
+	}
+}
+
+func TestSignAndVerifyRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+	SignRequest(req, []string{"current", "previous"}, body, now)
+
+	v := &Verifier{Secrets: []string{"previous"}, Now: fixedNow}
+	got, err := v.VerifyRequest(req)
+	if err != nil {
+		t.Fatalf("VerifyRequest() error = %v", err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Fatalf("VerifyRequest() body = %s, want %s", got, body)
+	}
+
+	// Replaying the captured request after the tolerance window must fail
+	replay := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+	replay.Header = req.Header.Clone()
+	v.Now = func() time.Time { return now.Add(DefaultTolerance + time.Second) }
+	if _, err := v.VerifyRequest(replay); !errors.Is(err, ErrStaleTimestamp) {
+		t.Fatalf("VerifyRequest() replay error = %v, want %v", err, ErrStaleTimestamp)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,10 +12,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/auth"
 	"github.com/yourusername/go-microservices/user-service/internal/config"
 	"github.com/yourusername/go-microservices/user-service/internal/database"
+	"github.com/yourusername/go-microservices/user-service/internal/events"
 	"github.com/yourusername/go-microservices/user-service/internal/handlers"
 	"github.com/yourusername/go-microservices/user-service/internal/middleware"
+	"github.com/yourusername/go-microservices/user-service/internal/notifications"
 	"github.com/yourusername/go-microservices/user-service/internal/repository"
 	"github.com/yourusername/go-microservices/user-service/internal/service"
 )
@@ -24,6 +28,14 @@ func main() {
 	logger.SetFormatter(&logrus.JSONFormatter{})
 	logger.SetOutput(os.Stdout)
 
+	// user-service migrate ... manages the schema instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			logger.Fatalf("Migrate failed: %v", err)
+		}
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -37,22 +49,65 @@ func main() {
 	}
 	defer db.Close()
 
-	// Run migrations
-	if err := database.MigrateUp(cfg.DatabaseURL()); err != nil {
-		logger.Fatalf("Failed to run migrations: %v", err)
-	}
-
-	// Initialize repository
+	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	tokenRepo := repository.NewTokenRepository(db)
+	verificationRepo := repository.NewVerificationRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	privacyRepo := repository.NewPrivacyRepository(db)
+	importRepo := repository.NewImportRepository(db)
 
-	// Initialize service
-	userService := service.NewUserService(userRepo)
+	// Initialize services
+	verificationService := service.NewVerificationService(userRepo, verificationRepo, cfg.VerificationTTL, cfg.AppURL)
+	userService := service.NewUserService(userRepo, verificationService)
+	passwordResetService := service.NewPasswordResetService(userRepo, passwordResetRepo, cfg.PasswordResetTTL, cfg.AppURL)
+	tokenIssuer := auth.NewTokenIssuer(cfg.JWTSecret, cfg.AccessTokenTTL)
+	lockoutService := service.NewLockoutService(userRepo, loginAttemptRepo, cfg.LoginLockoutDuration)
+	authService := service.NewAuthService(userRepo, tokenRepo, sessionRepo, mfaRepo, lockoutService, tokenIssuer, cfg.RefreshTokenTTL, cfg.RequireVerifiedEmail)
+	mfaService := service.NewMFAService(userRepo, mfaRepo, cfg.MFAIssuer)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+	sessionService := service.NewSessionService(sessionRepo)
+	introspectionService := service.NewIntrospectionService(apiKeyRepo, sessionRepo, tokenIssuer)
+	auditService := service.NewAuditService(auditRepo)
+
+	// Data exports include the notification service's data when it is configured
+	var notificationClient *notifications.Client
+	if cfg.NotificationServiceURL != "" {
+		notificationClient = notifications.NewClient(cfg.NotificationServiceURL, cfg.ServiceToken, cfg.EventTimeout)
+	}
+	privacyService := service.NewPrivacyService(privacyRepo, notificationClient)
+	importService := service.NewImportService(importRepo, verificationService)
+
+	// Relay user events to the notification service once the schema is ready
+	var relay *events.Relay
+	if cfg.NotificationServiceURL != "" {
+		relay = events.NewRelay(eventRepo, cfg.NotificationServiceURL+"/events/users", cfg.ServiceToken, cfg.EventTimeout, cfg.EventPollInterval, logger)
+	} else {
+		logger.Warn("NOTIFICATION_SERVICE_URL is not set, user events stay in the outbox")
+	}
 
 	// Initialize router
 	router := gin.New()
+	// Client IPs feed rate limits and the audit log, so X-Forwarded-For is only believed
+	// from the gateway
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 	router.Use(gin.Recovery())
+	router.Use(middleware.RequestID())
 	router.Use(middleware.Logger(logger))
 	router.Use(middleware.PrometheusMetrics())
+	// Exports stream for as long as the table takes, and imports upload whole files
+	router.Use(middleware.Timeout(cfg.RequestTimeout, "/users/export", "/users/import"))
+	// Only probes and metrics are served until the schema is ready
+	readiness := &middleware.Readiness{}
+	router.Use(middleware.RequireReady(readiness, "/health", "/ready", "/metrics"))
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -62,21 +117,106 @@ func main() {
 		})
 	})
 
+	// Readiness endpoint, which fails until the schema has every migration this binary
+	// carries
+	router.GET("/ready", func(c *gin.Context) {
+		if !readiness.Ready() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "STARTING",
+				"name":   "user-service",
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"status": "READY",
+			"name":   "user-service",
+		})
+	})
+
 	// Prometheus metrics endpoint
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// API routes
-	userHandler := handlers.NewUserHandler(userService, logger)
+	userHandler := handlers.NewUserHandler(userService, cfg.BatchMaxItems, logger)
 	router.GET("/users", userHandler.GetUsers)
-	router.GET("/users/:id", userHandler.GetUser)
-	router.POST("/users", userHandler.CreateUser)
-	router.PUT("/users/:id", userHandler.UpdateUser)
-	router.DELETE("/users/:id", userHandler.DeleteUser)
+	// Mutations record the caller, when known, in the audit log
+	identified := router.Group("/", middleware.Identify(tokenIssuer, sessionRepo, cfg.ServiceToken))
+	// Past versions of a user, with as_of, are for the user and admins
+	identified.GET("/users/:id", userHandler.GetUser)
+	identified.POST("/users", userHandler.CreateUser)
+	// POST /users:batchGet and POST /users:batch
+	identified.POST("/users:action", userHandler.Batch)
+
+	verificationHandler := handlers.NewVerificationHandler(verificationService, logger)
+	router.POST("/verify-email", verificationHandler.VerifyEmail)
+
+	authHandler := handlers.NewAuthHandler(authService, lockoutService, logger)
+	router.POST("/auth/login", authHandler.Login)
+	router.POST("/auth/refresh", authHandler.Refresh)
+	router.POST("/auth/logout", authHandler.Logout)
+	router.POST("/auth/mfa/verify", authHandler.VerifyMFA)
 
-	// Create HTTP server
+	passwordResetHandler := handlers.NewPasswordResetHandler(passwordResetService, logger)
+	router.POST("/auth/password-reset", passwordResetHandler.RequestReset)
+	router.POST("/auth/password-reset/confirm", passwordResetHandler.ConfirmReset)
+
+	// Resolves gateway credentials for the gateway only, so that it cannot be used to test
+	// stolen tokens
+	introspectionHandler := handlers.NewIntrospectionHandler(introspectionService, logger)
+	router.POST("/auth/introspect", middleware.RequireServiceToken(cfg.ServiceToken), introspectionHandler.Introspect)
+
+	// Routes for authenticated callers
+	authenticated := router.Group("/", middleware.Authenticate(tokenIssuer, sessionRepo, logger))
+	mfaHandler := handlers.NewMFAHandler(mfaService, logger)
+	authenticated.POST("/auth/mfa/enroll", mfaHandler.Enroll)
+	authenticated.POST("/auth/mfa/confirm", mfaHandler.Confirm)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService, logger)
+	authenticated.GET("/api-keys", apiKeyHandler.GetAPIKeys)
+	authenticated.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+	authenticated.DELETE("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+
+	sessionHandler := handlers.NewSessionHandler(sessionService, logger)
+	authenticated.GET("/sessions", sessionHandler.GetSessions)
+	authenticated.DELETE("/sessions", sessionHandler.RevokeOtherSessions)
+	authenticated.DELETE("/sessions/:session_id", sessionHandler.RevokeSession)
+
+	authenticated.PUT("/users/:id", middleware.RequireSelfOrAdmin("id"), userHandler.UpdateUser)
+	authenticated.DELETE("/users/:id", middleware.RequireAdmin(), userHandler.DeleteUser)
+	authenticated.GET("/users/:id/history", middleware.RequireSelfOrAdmin("id"), userHandler.GetUserHistory)
+
+	privacyHandler := handlers.NewPrivacyHandler(privacyService, logger)
+	authenticated.POST("/users/:id/export", middleware.RequireSelfOrAdmin("id"), privacyHandler.ExportUser)
+	authenticated.POST("/users/:id/erase", middleware.RequireSelfOrAdmin("id"), privacyHandler.EraseUser)
+
+	authenticated.POST("/users/:id/verify-email/send", middleware.RequireSelfOrAdmin("id"), verificationHandler.SendVerification)
+
+	authenticated.GET("/users/export", middleware.RequireAdmin(), userHandler.ExportUsers)
+
+	importHandler := handlers.NewImportHandler(importService, logger)
+	authenticated.POST("/users/import", middleware.RequireAdmin(), importHandler.StartImport)
+	authenticated.GET("/users/import/:job_id", middleware.RequireAdmin(), importHandler.GetImport)
+	authenticated.GET("/users/import/:job_id/errors", middleware.RequireAdmin(), importHandler.GetImportErrors)
+
+	admin := authenticated.Group("/admin", middleware.RequireAdmin())
+	admin.DELETE("/users/:id/mfa", mfaHandler.Reset)
+	admin.POST("/users/:id/unlock", authHandler.Unlock)
+	admin.GET("/users/:id/sessions", sessionHandler.GetUserSessions)
+	admin.DELETE("/users/:id/sessions", sessionHandler.RevokeUserSessions)
+	admin.DELETE("/users/:id/sessions/:session_id", sessionHandler.RevokeUserSession)
+
+	auditHandler := handlers.NewAuditHandler(auditService, logger)
+	authenticated.GET("/audit", middleware.RequireAdmin(), auditHandler.GetAuditEntries)
+	authenticated.GET("/audit/verify", middleware.RequireAdmin(), auditHandler.VerifyAuditLog)
+
+	// Create HTTP server. Requests run under baseCtx so that their queries can be
+	// canceled when shutdown gives up on them.
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
 	server := &http.Server{
 		Addr:    ":" + cfg.Port,
 		Handler: router,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
 
 	// Start server in a goroutine
@@ -87,6 +227,15 @@ func main() {
 		}
 	}()
 
+	// Bring the schema up to date, or wait for another replica to, before taking traffic
+	if err := prepareSchema(cfg, db, logger); err != nil {
+		logger.Fatalf("Failed to prepare schema: %v", err)
+	}
+	readiness.SetReady()
+	if relay != nil {
+		relay.Start()
+	}
+
 	// Wait for interrupt signal to gracefully shut down the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -100,7 +249,18 @@ func main() {
 
 	// Shutdown the server
 	if err := server.Shutdown(ctx); err != nil {
-		logger.Fatalf("Server forced to shutdown: %v", err)
+		// Cancel the queries of the requests that outlived the grace period
+		cancelRequests()
+		logger.Errorf("Server forced to shutdown: %v", err)
+	}
+
+	// Let running imports finish their current batch; the rest of the file is reported
+	// as interrupted
+	importService.Stop()
+
+	// Let an in-flight event batch finish; undelivered events are sent after restart
+	if relay != nil {
+		relay.Stop()
 	}
 
 	logger.Info("Server exiting")
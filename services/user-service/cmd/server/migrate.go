@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/config"
+	"github.com/yourusername/go-microservices/user-service/internal/database"
+)
+
+// migrationsDir is where migrate create writes new migrations, relative to the service
+// root
+const migrationsDir = "migrations"
+
+// schemaPollInterval is how often a starting replica checks whether the schema is ready
+const schemaPollInterval = time.Second
+
+var errMigrateUsage = errors.New("usage: user-service migrate up | down N | status | force V | create NAME | lint")
+
+// runMigrate runs the migrate subcommand with the arguments that follow it
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	// create and lint only work on files, so they need no database
+	if args[0] == "lint" && len(args) == 1 {
+		return lintMigrations()
+	}
+	if args[0] == "create" {
+		if len(args) != 2 {
+			return errMigrateUsage
+		}
+		paths, err := database.CreateMigration(migrationsDir, args[1])
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			fmt.Println("Created", path)
+		}
+		return nil
+	}
+
+	var run func(dsn string, lockTimeout time.Duration) error
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		run = database.MigrateUp
+	case args[0] == "down" && len(args) == 2:
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errMigrateUsage
+		}
+		run = func(dsn string, lockTimeout time.Duration) error {
+			return database.MigrateDown(dsn, n, lockTimeout)
+		}
+	case args[0] == "force" && len(args) == 2:
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errMigrateUsage
+		}
+		run = func(dsn string, lockTimeout time.Duration) error {
+			return database.ForceMigration(dsn, version, lockTimeout)
+		}
+	case args[0] == "status" && len(args) == 1:
+		run = func(dsn string, lockTimeout time.Duration) error { return nil }
+	default:
+		return errMigrateUsage
+	}
+
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		return err
+	}
+	if err := run(cfg.DatabaseURL(), cfg.MigrationLockTimeout); err != nil {
+		return err
+	}
+	return printMigrationStatus(cfg.DatabaseURL())
+}
+
+// printMigrationStatus prints the schema version and whether each embedded migration
+// has been applied
+func printMigrationStatus(dsn string) error {
+	status, err := database.GetMigrationStatus(dsn)
+	if err != nil {
+		return err
+	}
+
+	dirty := ""
+	if status.Dirty {
+		dirty = " (dirty, fix the schema and run force)"
+	}
+	fmt.Printf("Version: %d%s\n", status.Version, dirty)
+	for _, m := range status.Migrations {
+		state := "applied"
+		if m.Version > status.Version {
+			state = "pending"
+		}
+		fmt.Printf("  %06d %-40s %s\n", m.Version, m.Name, state)
+	}
+	return nil
+}
+
+// lintMigrations reports the destructive statements in migrations that are not marked as
+// contract, and fails if there are any
+func lintMigrations() error {
+	issues, err := database.LintMigrations(os.DirFS(migrationsDir))
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("%d destructive statements outside contract migrations", len(issues))
+	}
+	return nil
+}
+
+// prepareSchema applies the pending migrations when auto-migration is on, then waits
+// until the schema has every migration this binary carries, whichever process applies
+// them
+func prepareSchema(cfg *config.Config, db *sqlx.DB, logger *logrus.Logger) error {
+	if cfg.AutoMigrate {
+		if err := database.MigrateUp(cfg.DatabaseURL(), cfg.MigrationLockTimeout); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.SchemaWaitTimeout)
+	defer cancel()
+	version, err := database.WaitForSchema(ctx, db, schemaPollInterval)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Schema is at version %d", version)
+	return nil
+}
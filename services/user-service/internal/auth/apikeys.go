@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, so that keys are recognisable in configuration
+// and secret scanners
+const APIKeyPrefix = "gmk_"
+
+// NewAPIKey returns a random API key of the form gmk_<id>_<secret>, the prefix that
+// identifies it and the hash of its secret to store
+func NewAPIKey() (key, prefix, secretHash string, err error) {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+	secret, hash, err := NewOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+	prefix = APIKeyPrefix + hex.EncodeToString(id)
+	return prefix + "_" + secret, prefix, hash, nil
+}
+
+// ParseAPIKey splits an API key into its prefix and secret
+func ParseAPIKey(key string) (prefix, secret string, ok bool) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return "", "", false
+	}
+	i := strings.Index(key[len(APIKeyPrefix):], "_")
+	if i < 0 {
+		return "", "", false
+	}
+	i += len(APIKeyPrefix)
+	return key[:i], key[i+1:], key[i+1:] != ""
+}
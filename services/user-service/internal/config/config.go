@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds the application configuration
@@ -14,6 +17,40 @@ type Config struct {
 	DBUser   string
 	DBPass   string
 	DBName   string
+
+	// NotificationServiceURL receives user events; events stay in the outbox while it is unset
+	NotificationServiceURL string
+	EventTimeout           time.Duration
+	EventPollInterval      time.Duration
+
+	JWTSecret            string
+	AccessTokenTTL       time.Duration
+	RefreshTokenTTL      time.Duration
+	RequireVerifiedEmail bool
+	VerificationTTL      time.Duration
+	PasswordResetTTL     time.Duration
+	LoginLockoutDuration time.Duration
+	// MFAIssuer names the service in authenticator apps
+	MFAIssuer string
+	// AppURL is the base URL of the web application that links in emails point to
+	AppURL string
+	// BatchMaxItems bounds the IDs of a batch get and the operations of a batch
+	BatchMaxItems int
+	// RequestTimeout bounds the time a request's queries may take
+	RequestTimeout time.Duration
+	// ServiceToken is shared with the gateway and the notification service. Calls between
+	// them carry it in the X-Service-Token header.
+	ServiceToken string
+	// TrustedProxies are the addresses or CIDRs, normally the gateway's, whose
+	// X-Forwarded-For header is believed when taking the client IP
+	TrustedProxies []string
+	// AutoMigrate applies pending migrations on start; when off, the schema is managed
+	// with the migrate subcommand
+	AutoMigrate bool
+	// MigrationLockTimeout bounds the wait for other replicas to finish migrating
+	MigrationLockTimeout time.Duration
+	// SchemaWaitTimeout bounds the wait on start for the schema this binary needs
+	SchemaWaitTimeout time.Duration
 }
 
 // Load loads the configuration from environment variables
@@ -23,6 +60,109 @@ func Load() (*Config, error) {
 		port = "8081" // Default port
 	}
 
+	db, err := LoadDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	eventPollInterval, err := durationEnv("EVENT_POLL_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		return nil, errors.New("JWT_SECRET environment variable is required")
+	}
+
+	accessTokenTTL, err := durationEnv("ACCESS_TOKEN_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshTokenTTL, err := durationEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	verificationTTL, err := durationEnv("EMAIL_VERIFICATION_TTL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordResetTTL, err := durationEnv("PASSWORD_RESET_TTL", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	loginLockoutDuration, err := durationEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	mfaIssuer := os.Getenv("MFA_ISSUER")
+	if mfaIssuer == "" {
+		mfaIssuer = "go-microservices"
+	}
+
+	serviceToken := os.Getenv("SERVICE_TOKEN")
+	if serviceToken == "" {
+		return nil, errors.New("SERVICE_TOKEN environment variable is required")
+	}
+
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:8080"
+	}
+
+	batchMaxItems, err := intEnv("BATCH_MAX_ITEMS", 100)
+	if err != nil {
+		return nil, err
+	}
+
+	requestTimeout, err := durationEnv("REQUEST_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaWaitTimeout, err := durationEnv("SCHEMA_WAIT_TIMEOUT", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		Port:     port,
+		DBHost:   db.DBHost,
+		DBPort:   db.DBPort,
+		DBUser:   db.DBUser,
+		DBPass:   db.DBPass,
+		DBName:   db.DBName,
+
+		NotificationServiceURL: os.Getenv("NOTIFICATION_SERVICE_URL"),
+		EventTimeout:           10 * time.Second,
+		EventPollInterval:      eventPollInterval,
+
+		JWTSecret:            jwtSecret,
+		AccessTokenTTL:       accessTokenTTL,
+		RefreshTokenTTL:      refreshTokenTTL,
+		RequireVerifiedEmail: os.Getenv("AUTH_REQUIRE_VERIFIED_EMAIL") == "true",
+		VerificationTTL:      verificationTTL,
+		PasswordResetTTL:     passwordResetTTL,
+		LoginLockoutDuration: loginLockoutDuration,
+		MFAIssuer:            mfaIssuer,
+		AppURL:               appURL,
+		BatchMaxItems:        batchMaxItems,
+		RequestTimeout:       requestTimeout,
+		ServiceToken:         serviceToken,
+		TrustedProxies:       listEnv("TRUSTED_PROXIES"),
+		AutoMigrate:          os.Getenv("AUTO_MIGRATE") != "false",
+		MigrationLockTimeout: db.MigrationLockTimeout,
+		SchemaWaitTimeout:    schemaWaitTimeout,
+	}, nil
+}
+
+// LoadDatabase loads only the database settings, for commands that need nothing else
+func LoadDatabase() (*Config, error) {
 	dbHost := os.Getenv("DB_HOST")
 	if dbHost == "" {
 		return nil, errors.New("DB_HOST environment variable is required")
@@ -48,13 +188,19 @@ func Load() (*Config, error) {
 		return nil, errors.New("DB_NAME environment variable is required")
 	}
 
+	migrationLockTimeout, err := durationEnv("MIGRATION_LOCK_TIMEOUT", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
-		Port:     port,
-		DBHost:   dbHost,
-		DBPort:   dbPort,
-		DBUser:   dbUser,
-		DBPass:   dbPass,
-		DBName:   dbName,
+		DBHost: dbHost,
+		DBPort: dbPort,
+		DBUser: dbUser,
+		DBPass: dbPass,
+		DBName: dbName,
+
+		MigrationLockTimeout: migrationLockTimeout,
 	}, nil
 }
 
@@ -62,4 +208,41 @@ func Load() (*Config, error) {
 func (c *Config) DatabaseURL() string {
 	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
 		c.DBUser, c.DBPass, c.DBHost, c.DBPort, c.DBName)
+}
+
+// durationEnv parses a Go duration such as "15m" from an environment variable
+func durationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration such as 30s", key)
+	}
+	return d, nil
+}
+
+// listEnv splits a comma-separated environment variable, returning nil when it is unset
+func listEnv(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// intEnv parses a positive integer from an environment variable
+func intEnv(key string, fallback int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", key)
+	}
+	return n, nil
 }
\ No newline at end of file
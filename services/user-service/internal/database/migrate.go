@@ -0,0 +1,239 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/user-service/migrations"
+)
+
+// migrationLockID keys the advisory lock that takes replicas and migrate commands through
+// migrations one at a time. golang-migrate's own lock uses a different key.
+const migrationLockID int64 = 5210474190318
+
+// ErrMigrationLockTimeout is returned when another process held the migration lock for
+// longer than the lock timeout
+var ErrMigrationLockTimeout = errors.New("timed out waiting for the migration lock")
+
+// Migration is one of the migrations embedded in the binary
+type Migration struct {
+	Version uint
+	Name    string
+}
+
+// MigrationStatus is the schema version of a database next to the embedded migrations
+type MigrationStatus struct {
+	// Version is the last applied migration, 0 when none has been applied
+	Version uint
+	// Dirty is set when a migration failed part way; it stays set until force clears it
+	Dirty      bool
+	Migrations []Migration
+}
+
+// MigrateUp applies the pending migrations. It waits up to lockTimeout for other
+// processes to finish migrating first.
+func MigrateUp(dsn string, lockTimeout time.Duration) error {
+	return withMigrate(dsn, lockTimeout, func(m *migrate.Migrate) error {
+		if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+		return nil
+	})
+}
+
+// MigrateDown reverts the last n applied migrations, waiting up to lockTimeout for the
+// migration lock
+func MigrateDown(dsn string, n int, lockTimeout time.Duration) error {
+	if n <= 0 {
+		return errors.New("the number of migrations to revert must be positive")
+	}
+	return withMigrate(dsn, lockTimeout, func(m *migrate.Migrate) error {
+		if err := m.Steps(-n); err != nil {
+			return fmt.Errorf("failed to revert migrations: %w", err)
+		}
+		return nil
+	})
+}
+
+// ForceMigration records version as applied and clears the dirty flag without running
+// anything, after a failed migration has been cleaned up by hand. A version of -1 means
+// that no migration is applied. It waits up to lockTimeout for the migration lock.
+func ForceMigration(dsn string, version int, lockTimeout time.Duration) error {
+	return withMigrate(dsn, lockTimeout, func(m *migrate.Migrate) error {
+		if err := m.Force(version); err != nil {
+			return fmt.Errorf("failed to force migration version: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetMigrationStatus gets the schema version of the database and the embedded migrations
+func GetMigrationStatus(dsn string) (*MigrationStatus, error) {
+	embedded, err := EmbeddedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MigrationStatus{Migrations: embedded}
+	err = withMigrate(dsn, 0, func(m *migrate.Migrate) error {
+		version, dirty, err := m.Version()
+		if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+			return fmt.Errorf("failed to get migration version: %w", err)
+		}
+		status.Version, status.Dirty = version, dirty
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// EmbeddedMigrations lists the migrations embedded in the binary, oldest first
+func EmbeddedMigrations() ([]Migration, error) {
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+	defer src.Close()
+
+	var list []Migration
+	version, err := src.First()
+	for err == nil {
+		r, name, readErr := src.ReadUp(version)
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read migration %d: %w", version, readErr)
+		}
+		r.Close()
+		list = append(list, Migration{Version: version, Name: name})
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+	return list, nil
+}
+
+// migrationNameChars matches the runs of characters that are replaced in the names of
+// new migrations
+var migrationNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// CreateMigration writes an empty up and down migration named name to dir, numbered
+// after the migrations already there, and returns their paths. The files are embedded
+// when the binary is next built.
+func CreateMigration(dir, name string) ([]string, error) {
+	name = strings.Trim(migrationNameChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("the migration name must contain letters or digits")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	var last uint
+	for _, entry := range entries {
+		m, err := source.DefaultParse(entry.Name())
+		if err == nil && m.Version > last {
+			last = m.Version
+		}
+	}
+
+	var paths []string
+	for _, direction := range []source.Direction{source.Up, source.Down} {
+		path := filepath.Join(dir, fmt.Sprintf("%06d_%s.%s.sql", last+1, name, direction))
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create migration: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return nil, fmt.Errorf("failed to create migration: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// withMigrate runs fn with a migrate instance that reads the embedded migrations. With a
+// positive lockTimeout, fn runs under the migration lock.
+func withMigrate(dsn string, lockTimeout time.Duration, fn func(m *migrate.Migrate) error) error {
+	if lockTimeout > 0 {
+		unlock, err := lockMigrations(dsn, lockTimeout)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	driver, err := postgres.WithInstance(db.DB, &postgres.Config{})
+	if err != nil {
+		return fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		return fmt.Errorf("failed to create migration instance: %w", err)
+	}
+	defer m.Close()
+
+	return fn(m)
+}
+
+// lockMigrations takes the migration lock, waiting up to timeout for it. The lock is held
+// on a connection of its own, as closing the migrate instance closes its database; the
+// returned function releases it.
+func lockMigrations(dsn string, timeout time.Duration) (func(), error) {
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn, err := db.Conn(ctx)
+	if err == nil {
+		_, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID)
+		if err != nil {
+			conn.Close()
+		}
+	}
+	if err != nil {
+		db.Close()
+		if ctx.Err() != nil {
+			return nil, ErrMigrationLockTimeout
+		}
+		return nil, fmt.Errorf("failed to take migration lock: %w", err)
+	}
+
+	return func() {
+		// Closing the database ends the session, which releases the lock even if the
+		// unlock fails
+		conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+		conn.Close()
+		db.Close()
+	}, nil
+}
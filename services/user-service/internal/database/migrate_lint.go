@@ -0,0 +1,95 @@
+package database
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ContractMarker marks a migration as the contract phase of a schema change, the one
+// allowed to remove or rewrite what older binaries still use. Such migrations ship only
+// once no running binary needs the old schema.
+const ContractMarker = "-- migrate:contract"
+
+// destructiveStatements are the statements that break binaries written for the schema
+// before them
+var destructiveStatements = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"DROP COLUMN", regexp.MustCompile(`(?i)\bDROP\s+COLUMN\b`)},
+	{"DROP TABLE", regexp.MustCompile(`(?i)\bDROP\s+TABLE\b`)},
+	{"column type change", regexp.MustCompile(`(?i)\bALTER\s+(COLUMN\s+)?\S+\s+(SET\s+DATA\s+)?TYPE\b`)},
+	{"rename", regexp.MustCompile(`(?i)\bALTER\s+TABLE\b[^;]*\bRENAME\b`)},
+}
+
+// sqlComments matches line and block comments
+var sqlComments = regexp.MustCompile(`(?s)--[^\n]*|/\*.*?\*/`)
+
+// LintIssue is a destructive statement in a migration that is not marked as contract
+type LintIssue struct {
+	File      string
+	Line      int
+	Statement string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s:%d: %s in a migration not marked %q", i.File, i.Line, i.Statement, ContractMarker)
+}
+
+// LintMigrations checks the up migrations in fsys for destructive statements. Expand
+// migrations must keep the schema usable by the binary before them, so each destructive
+// statement is an issue unless its file has a line reading ContractMarker.
+func LintMigrations(fsys fs.FS) ([]LintIssue, error) {
+	files, err := fs.Glob(fsys, "*.up.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	sort.Strings(files)
+
+	var issues []LintIssue
+	for _, file := range files {
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", file, err)
+		}
+		issues = append(issues, lintMigration(file, string(content))...)
+	}
+	return issues, nil
+}
+
+// lintMigration checks one up migration
+func lintMigration(file, sql string) []LintIssue {
+	for _, line := range strings.Split(sql, "\n") {
+		if strings.TrimSpace(line) == ContractMarker {
+			return nil
+		}
+	}
+
+	// Blank out comments, keeping their newlines so that offsets map to the same lines
+	code := sqlComments.ReplaceAllStringFunc(sql, func(comment string) string {
+		return strings.Map(func(r rune) rune {
+			if r == '\n' {
+				return r
+			}
+			return ' '
+		}, comment)
+	})
+
+	var issues []LintIssue
+	for _, statement := range destructiveStatements {
+		for _, match := range statement.pattern.FindAllStringIndex(code, -1) {
+			issues = append(issues, LintIssue{
+				File:      file,
+				Line:      strings.Count(code[:match[0]], "\n") + 1,
+				Statement: statement.name,
+			})
+		}
+	}
+	sort.Slice(issues, func(a, b int) bool {
+		return issues[a].Line < issues[b].Line
+	})
+	return issues
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/yourusername/go-microservices/user-service/migrations"
+)
+
+func TestEmbeddedMigrationsPassLint(t *testing.T) {
+	issues, err := LintMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("LintMigrations: %v", err)
+	}
+	for _, issue := range issues {
+		t.Error(issue)
+	}
+}
+
+func TestLintMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000001_expand.up.sql": {Data: []byte(`ALTER TABLE users ADD COLUMN nickname TEXT;
+-- DROP COLUMN in a comment is fine
+ALTER TABLE users ALTER COLUMN nickname SET NOT NULL;
+`)},
+		"000002_destructive.up.sql": {Data: []byte(`ALTER TABLE users
+    DROP COLUMN nickname;
+ALTER TABLE users ALTER COLUMN name TYPE TEXT;
+ALTER TABLE users ALTER name SET DATA TYPE VARCHAR(100);
+DROP TABLE IF EXISTS old_sessions;
+ALTER TABLE users RENAME COLUMN name TO full_name;
+`)},
+		"000002_destructive.down.sql": {Data: []byte(`DROP TABLE users;`)},
+		"000003_contract.up.sql": {Data: []byte(ContractMarker + `
+ALTER TABLE users DROP COLUMN legacy_name;
+`)},
+	}
+
+	issues, err := LintMigrations(fsys)
+	if err != nil {
+		t.Fatalf("LintMigrations: %v", err)
+	}
+	want := []LintIssue{
+		{"000002_destructive.up.sql", 2, "DROP COLUMN"},
+		{"000002_destructive.up.sql", 3, "column type change"},
+		{"000002_destructive.up.sql", 4, "column type change"},
+		{"000002_destructive.up.sql", 5, "DROP TABLE"},
+		{"000002_destructive.up.sql", 6, "rename"},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("LintMigrations = %v, want %v", issues, want)
+	}
+	for i := range want {
+		if issues[i] != want[i] {
+			t.Errorf("issue %d = %v, want %v", i, issues[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// RequiredSchemaVersion returns the version of the newest embedded migration, which the
+// binary needs before it can take traffic
+func RequiredSchemaVersion() (uint, error) {
+	embedded, err := EmbeddedMigrations()
+	if err != nil {
+		return 0, err
+	}
+	if len(embedded) == 0 {
+		return 0, nil
+	}
+	return embedded[len(embedded)-1].Version, nil
+}
+
+// SchemaVersion gets the applied migration version, 0 before the first migration, and
+// whether a migration is running or failed part way
+func SchemaVersion(ctx context.Context, db *sqlx.DB) (uint, bool, error) {
+	var row struct {
+		Version int64 `db:"version"`
+		Dirty   bool  `db:"dirty"`
+	}
+	err := db.GetContext(ctx, &row, `SELECT version, dirty FROM schema_migrations LIMIT 1`)
+	var pqErr *pq.Error
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.As(err, &pqErr) && pqErr.Code == "42P01":
+		// No migration has run, or none has finished creating the table
+		return 0, false, nil
+	case err != nil:
+		return 0, false, fmt.Errorf("failed to get schema version: %w", err)
+	case row.Version < 0:
+		// golang-migrate records a forced version of -1 as no migration
+		return 0, row.Dirty, nil
+	}
+	return uint(row.Version), row.Dirty, nil
+}
+
+// WaitForSchema checks every interval until the schema has at least the required version
+// and no migration is running, and returns the version it found. It gives up when ctx
+// ends. Newer versions are accepted, since migrations only expand the schema until a
+// contract migration, and those ship once no running binary needs what they remove.
+func WaitForSchema(ctx context.Context, db *sqlx.DB, interval time.Duration) (uint, error) {
+	required, err := RequiredSchemaVersion()
+	if err != nil {
+		return 0, err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		version, dirty, err := SchemaVersion(ctx, db)
+		if err == nil && version >= required && !dirty {
+			return version, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return 0, fmt.Errorf("schema did not reach version %d: %w", required, err)
+			}
+			return 0, fmt.Errorf("schema is at version %d (dirty: %t), want %d: %w", version, dirty, required, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
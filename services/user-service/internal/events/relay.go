@@ -0,0 +1,158 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+)
+
+const (
+	// batchSize is how many events are claimed at a time
+	batchSize = 20
+	// maxBackoff caps the delay between delivery attempts of a failing event
+	maxBackoff = 10 * time.Minute
+)
+
+// Relay delivers user events from the outbox to a subscriber endpoint. Delivery is
+// at-least-once; subscribers deduplicate by event ID.
+type Relay struct {
+	repo         *repository.EventRepository
+	endpoint     string
+	serviceToken string
+	client       *http.Client
+	interval     time.Duration
+	// lease is how long claimed events are reserved, enough to deliver a whole batch
+	lease  time.Duration
+	logger *logrus.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRelay creates a new Relay posting events to endpoint, which only accepts requests
+// carrying serviceToken
+func NewRelay(repo *repository.EventRepository, endpoint, serviceToken string, timeout, interval time.Duration, logger *logrus.Logger) *Relay {
+	return &Relay{
+		repo:         repo,
+		endpoint:     endpoint,
+		serviceToken: serviceToken,
+		client:       &http.Client{Timeout: timeout},
+		interval:     interval,
+		lease:        batchSize*timeout + time.Minute,
+		logger:       logger,
+	}
+}
+
+// Start launches the relay
+func (r *Relay) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run(ctx)
+	}()
+}
+
+// Stop stops the relay and waits for an in-flight batch to finish
+func (r *Relay) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
+func (r *Relay) run(ctx context.Context) {
+	for {
+		claimed, err := r.deliverPending()
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to deliver user events")
+		}
+
+		// Keep draining while full batches come in
+		if claimed == batchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+// deliverPending claims a batch of due events, delivers them and records each outcome. A
+// failed event is retried after backoff. It returns how many events were claimed.
+func (r *Relay) deliverPending() (int, error) {
+	events, err := r.repo.ClaimPending(batchSize, r.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range events {
+		event := &events[i]
+		if err := r.deliver(event); err != nil {
+			next := time.Now().Add(backoff(event.Attempts + 1))
+			if err := r.repo.MarkFailed(event, err.Error(), next); err != nil {
+				return len(events), err
+			}
+			continue
+		}
+		if err := r.repo.MarkDelivered(event); err != nil {
+			return len(events), err
+		}
+	}
+	return len(events), nil
+}
+
+func (r *Relay) deliver(event *models.UserEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Service-Token", r.serviceToken)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		r.logger.WithError(err).WithField("event_id", event.ID).Warn("Failed to deliver user event")
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		r.logger.WithField("event_id", event.ID).WithField("status_code", resp.StatusCode).Warn("Subscriber rejected user event")
+		return fmt.Errorf("subscriber returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff doubles the delay from one second per failed attempt, up to maxBackoff
+func backoff(attempts int) time.Duration {
+	delay := time.Second
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
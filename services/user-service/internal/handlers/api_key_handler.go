@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/middleware"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/service"
+)
+
+// APIKeyHandler handles API key requests
+type APIKeyHandler struct {
+	service *service.APIKeyService
+	logger  *logrus.Logger
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler
+func NewAPIKeyHandler(service *service.APIKeyService, logger *logrus.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateAPIKey issues an API key for the caller
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	key, err := h.service.Create(c.GetString(middleware.UserIDKey), &req, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, service.ErrExpiryInPast) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to create API key")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create API key",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// GetAPIKeys gets the caller's API keys
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	keys, err := h.service.List(c.GetString(middleware.UserIDKey))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get API keys")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get API keys",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey revokes one of the caller's API keys
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	if err := h.service.Revoke(c.GetString(middleware.UserIDKey), c.Param("id"), c.ClientIP()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "API key not found",
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to revoke API key")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke API key",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/service"
+)
+
+// AuditHandler handles audit log requests
+type AuditHandler struct {
+	service *service.AuditService
+	logger  *logrus.Logger
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(service *service.AuditService, logger *logrus.Logger) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetAuditEntries gets a page of audit entries, optionally filtered by target, actor and
+// an RFC 3339 since time
+func (h *AuditHandler) GetAuditEntries(c *gin.Context) {
+	limit, offset, ok := pagination(c)
+	if !ok {
+		return
+	}
+
+	filter := &models.AuditFilter{
+		TargetID: c.Query("target"),
+		ActorID:  c.Query("actor"),
+	}
+	if v := c.Query("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid since",
+			})
+			return
+		}
+		filter.Since = &since
+	}
+
+	entries, err := h.service.List(filter, limit, offset)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get audit log")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get audit log",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// VerifyAuditLog checks the audit log hash chain
+func (h *AuditHandler) VerifyAuditLog(c *gin.Context) {
+	result, err := h.service.Verify()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to verify audit log")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to verify audit log",
+		})
+		return
+	}
+
+	if !result.Valid {
+		h.logger.WithField("broken_at", *result.BrokenAt).Error("Audit log hash chain is broken")
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/middleware"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/service"
+)
+
+// AuthHandler handles login and token requests
+type AuthHandler struct {
+	service *service.AuthService
+	lockout *service.LockoutService
+	logger  *logrus.Logger
+}
+
+// NewAuthHandler creates a new AuthHandler
+func NewAuthHandler(service *service.AuthService, lockout *service.LockoutService, logger *logrus.Logger) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+		lockout: lockout,
+		logger:  logger,
+	}
+}
+
+// Login exchanges an email and password for an access and a refresh token
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	tokens, challenge, err := h.service.Login(c.Request.Context(), &req, client(c))
+	if err != nil {
+		h.respondAuthError(c, err, "Failed to log in")
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// VerifyMFA completes a login with MFA by answering its challenge
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req models.VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	tokens, err := h.service.VerifyMFA(&req, client(c))
+	if err != nil {
+		h.respondAuthError(c, err, "Failed to verify MFA code")
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Refresh exchanges a refresh token for new tokens
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	tokens, err := h.service.Refresh(c.Request.Context(), req.RefreshToken, client(c))
+	if err != nil {
+		h.respondAuthError(c, err, "Failed to refresh tokens")
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Unlock lifts the lockout of a user's account
+func (h *AuthHandler) Unlock(c *gin.Context) {
+	if err := h.lockout.Unlock(c.Request.Context(), c.GetString(middleware.UserIDKey), c.Param("id"), c.ClientIP()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to unlock account")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to unlock account",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Logout revokes a refresh token
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	if err := h.service.Logout(req.RefreshToken); err != nil {
+		h.logger.WithError(err).Error("Failed to log out")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to log out",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondAuthError maps authentication failures to responses
+func (h *AuthHandler) respondAuthError(c *gin.Context, err error, message string) {
+	var throttled *service.ThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		retryAfter := math.Ceil(time.Until(throttled.Until).Seconds())
+		c.Header("Retry-After", strconv.Itoa(int(math.Max(retryAfter, 1))))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error": "Too many failed login attempts",
+		})
+	case errors.Is(err, service.ErrInvalidCredentials), errors.Is(err, service.ErrInvalidToken),
+		errors.Is(err, service.ErrInvalidMFACode):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrEmailNotVerified):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		h.logger.WithError(err).Error(message)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
+
+// client describes where a request comes from
+func client(c *gin.Context) models.Client {
+	return models.Client{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
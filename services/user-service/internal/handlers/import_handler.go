@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/service"
+)
+
+// maxImportSize bounds the size of an import file
+const maxImportSize = 100 << 20
+
+// ImportHandler handles bulk user import requests
+type ImportHandler struct {
+	service *service.ImportService
+	logger  *logrus.Logger
+}
+
+// NewImportHandler creates a new ImportHandler
+func NewImportHandler(service *service.ImportService, logger *logrus.Logger) *ImportHandler {
+	return &ImportHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// StartImport starts importing the CSV or NDJSON file in the request body. The format
+// comes from the format query parameter or else the Content-Type, and dry_run=true only
+// validates the file.
+func (h *ImportHandler) StartImport(c *gin.Context) {
+	format := c.Query("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		switch mediaType {
+		case "text/csv":
+			format = models.ImportCSV
+		case "application/x-ndjson", "application/ndjson":
+			format = models.ImportNDJSON
+		}
+	}
+
+	dryRun := false
+	if v := c.Query("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid dry_run",
+			})
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	job, err := h.service.Start(format, dryRun, body, actor(c))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.Is(err, service.ErrInvalidImportFormat):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		case errors.As(err, &tooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": "Import file is too large",
+			})
+		default:
+			h.logger.WithError(err).Error("Failed to start import")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to start import",
+			})
+		}
+		return
+	}
+
+	c.Header("Location", "/users/import/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetImport gets the status and progress of an import job
+func (h *ImportHandler) GetImport(c *gin.Context) {
+	job, err := h.service.Get(c.Param("job_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// GetImportErrors downloads the row errors of an import job as CSV
+func (h *ImportHandler) GetImportErrors(c *gin.Context) {
+	jobID := c.Param("job_id")
+
+	rowErrors, err := h.service.Errors(jobID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", `attachment; filename="import-`+jobID+`-errors.csv"`)
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"row", "email", "error"})
+	for _, rowError := range rowErrors {
+		writer.Write([]string{strconv.Itoa(rowError.RowNumber), rowError.Email, rowError.Error})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		h.logger.WithError(err).Warn("Failed to write import errors")
+	}
+}
+
+func (h *ImportHandler) respondError(c *gin.Context, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Import job not found",
+		})
+		return
+	}
+	h.logger.WithError(err).Error("Failed to get import job")
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Failed to get import job",
+	})
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/service"
+)
+
+// IntrospectionHandler handles credential introspection requests from the gateway
+type IntrospectionHandler struct {
+	service *service.IntrospectionService
+	logger  *logrus.Logger
+}
+
+// NewIntrospectionHandler creates a new IntrospectionHandler
+func NewIntrospectionHandler(service *service.IntrospectionService, logger *logrus.Logger) *IntrospectionHandler {
+	return &IntrospectionHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Introspect resolves an API key or access token to the identity behind it
+func (h *IntrospectionHandler) Introspect(c *gin.Context) {
+	var req models.IntrospectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	introspection, err := h.service.Introspect(req.Token)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to introspect credential")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to introspect credential",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, introspection)
+}
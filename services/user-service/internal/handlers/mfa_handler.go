@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/middleware"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/service"
+)
+
+// MFAHandler handles MFA enrollment and admin resets
+type MFAHandler struct {
+	service *service.MFAService
+	logger  *logrus.Logger
+}
+
+// NewMFAHandler creates a new MFAHandler
+func NewMFAHandler(service *service.MFAService, logger *logrus.Logger) *MFAHandler {
+	return &MFAHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Enroll starts MFA enrollment for the caller and returns their TOTP secret
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	enrollment, err := h.service.Enroll(c.Request.Context(), c.GetString(middleware.UserIDKey), c.ClientIP())
+	if err != nil {
+		h.respondMFAError(c, err, "Failed to start MFA enrollment")
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// Confirm enables MFA for the caller with a first code and returns their recovery codes
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	var req models.ConfirmMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	codes, err := h.service.Confirm(c.Request.Context(), c.GetString(middleware.UserIDKey), req.Code, c.ClientIP())
+	if err != nil {
+		h.respondMFAError(c, err, "Failed to enable MFA")
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// Reset turns MFA off for a user
+func (h *MFAHandler) Reset(c *gin.Context) {
+	if err := h.service.Reset(c.GetString(middleware.UserIDKey), c.Param("id"), c.ClientIP()); err != nil {
+		h.respondMFAError(c, err, "Failed to reset MFA")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondMFAError maps MFA failures to responses
+func (h *MFAHandler) respondMFAError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+	case errors.Is(err, service.ErrMFAAlreadyEnabled), errors.Is(err, service.ErrMFANotPending):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidMFACode):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		h.logger.WithError(err).Error(message)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
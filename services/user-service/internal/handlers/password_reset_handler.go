@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/service"
+)
+
+// PasswordResetHandler handles password reset requests
+type PasswordResetHandler struct {
+	service *service.PasswordResetService
+	logger  *logrus.Logger
+}
+
+// NewPasswordResetHandler creates a new PasswordResetHandler
+func NewPasswordResetHandler(service *service.PasswordResetService, logger *logrus.Logger) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RequestReset sends a reset link to the given address. It answers 202 whether or not
+// the address belongs to an account.
+func (h *PasswordResetHandler) RequestReset(c *gin.Context) {
+	var req models.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	if err := h.service.Request(c.Request.Context(), req.Email, c.ClientIP()); err != nil {
+		if errors.Is(err, service.ErrTooManyRequests) {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to request password reset")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to request password reset",
+		})
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ConfirmReset sets a new password with a reset token
+func (h *PasswordResetHandler) ConfirmReset(c *gin.Context) {
+	var req models.ConfirmPasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+
+	if err := h.service.Confirm(&req); err != nil {
+		if errors.Is(err, service.ErrInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to reset password")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to reset password",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/service"
+)
+
+// PrivacyHandler handles data export and erasure requests
+type PrivacyHandler struct {
+	service *service.PrivacyService
+	logger  *logrus.Logger
+}
+
+// NewPrivacyHandler creates a new PrivacyHandler
+func NewPrivacyHandler(service *service.PrivacyService, logger *logrus.Logger) *PrivacyHandler {
+	return &PrivacyHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ExportUser responds with everything held about a user as a JSON file download
+func (h *PrivacyHandler) ExportUser(c *gin.Context) {
+	id := c.Param("id")
+
+	export, err := h.service.Export(id, actor(c))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to export user")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to export user",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="user-`+id+`.json"`)
+	c.JSON(http.StatusOK, export)
+}
+
+// EraseUser erases a user's personal data. Repeating the request is safe.
+func (h *PrivacyHandler) EraseUser(c *gin.Context) {
+	erasure, err := h.service.Erase(c.Param("id"), actor(c))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to erase user")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to erase user",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, erasure)
+}
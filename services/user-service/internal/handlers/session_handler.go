@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/middleware"
+	"github.com/yourusername/go-microservices/user-service/internal/service"
+)
+
+// SessionHandler handles session requests
+type SessionHandler struct {
+	service *service.SessionService
+	logger  *logrus.Logger
+}
+
+// NewSessionHandler creates a new SessionHandler
+func NewSessionHandler(service *service.SessionService, logger *logrus.Logger) *SessionHandler {
+	return &SessionHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetSessions gets the caller's active sessions
+func (h *SessionHandler) GetSessions(c *gin.Context) {
+	h.list(c, c.GetString(middleware.UserIDKey))
+}
+
+// RevokeSession ends one of the caller's sessions
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	h.revoke(c, c.GetString(middleware.UserIDKey))
+}
+
+// RevokeOtherSessions ends all of the caller's sessions except the current one
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	userID := c.GetString(middleware.UserIDKey)
+	h.revokeAll(c, userID, c.GetString(middleware.SessionIDKey))
+}
+
+// GetUserSessions gets a user's active sessions
+func (h *SessionHandler) GetUserSessions(c *gin.Context) {
+	h.list(c, c.Param("id"))
+}
+
+// RevokeUserSession ends one of a user's sessions
+func (h *SessionHandler) RevokeUserSession(c *gin.Context) {
+	h.revoke(c, c.Param("id"))
+}
+
+// RevokeUserSessions ends all of a user's sessions
+func (h *SessionHandler) RevokeUserSessions(c *gin.Context) {
+	h.revokeAll(c, c.Param("id"), "")
+}
+
+func (h *SessionHandler) list(c *gin.Context, userID string) {
+	sessions, err := h.service.List(userID, c.GetString(middleware.SessionIDKey))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get sessions")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get sessions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (h *SessionHandler) revoke(c *gin.Context, userID string) {
+	actorID := c.GetString(middleware.UserIDKey)
+	if err := h.service.Revoke(actorID, userID, c.Param("session_id"), c.ClientIP()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Session not found",
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to revoke session")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke session",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *SessionHandler) revokeAll(c *gin.Context, userID, exceptID string) {
+	actorID := c.GetString(middleware.UserIDKey)
+	n, err := h.service.RevokeAll(actorID, userID, exceptID, c.ClientIP())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to revoke sessions")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke sessions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"revoked": n,
+	})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/go-microservices/user-service/internal/middleware"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/service"
+)
+
+// Batch serves the custom methods on the users collection, POST /users:batchGet and
+// POST /users:batch, which share a route
+func (h *UserHandler) Batch(c *gin.Context) {
+	switch c.Param("action") {
+	case ":batchGet":
+		h.BatchGetUsers(c)
+	case ":batch":
+		// Batches act on any user, so they are for admins
+		if c.GetString(middleware.UserIDKey) == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Authentication required",
+			})
+			return
+		}
+		if c.GetString(middleware.RoleKey) != models.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Admin access required",
+			})
+			return
+		}
+		h.BatchUsers(c)
+	default:
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Unknown method",
+		})
+	}
+}
+
+// BatchGetUsers gets many users by ID at once
+func (h *UserHandler) BatchGetUsers(c *gin.Context) {
+	var req models.BatchGetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+	if len(req.IDs) > h.batchMaxItems {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("At most %d IDs are allowed", h.batchMaxItems),
+		})
+		return
+	}
+
+	result, err := h.service.GetUsersByIDs(c.Request.Context(), req.IDs)
+	if err != nil {
+		if h.queryAborted(c, err) {
+			return
+		}
+		h.logger.WithError(err).Error("Failed to get users")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get users",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// BatchUsers applies a list of create, update and delete operations and reports the
+// outcome of each
+func (h *UserHandler) BatchUsers(c *gin.Context) {
+	var req models.BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithError(err).Error("Invalid request payload")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request payload",
+		})
+		return
+	}
+	if len(req.Operations) > h.batchMaxItems {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("At most %d operations are allowed", h.batchMaxItems),
+		})
+		return
+	}
+
+	outcomes := h.service.ApplyBatch(c.Request.Context(), req.Operations, req.Atomic, actor(c))
+	// Nobody is left to read the results of a canceled batch
+	if errors.Is(c.Request.Context().Err(), context.Canceled) {
+		c.AbortWithStatus(StatusClientClosedRequest)
+		return
+	}
+
+	response := models.BatchResponse{Results: make([]models.BatchResult, len(outcomes))}
+	for i, outcome := range outcomes {
+		op := req.Operations[i].Op
+		result := models.BatchResult{Index: i, Op: op, User: outcome.User}
+		switch {
+		case outcome.Err == nil && op == models.BatchCreate:
+			result.Status = http.StatusCreated
+		case outcome.Err == nil && op == models.BatchDelete:
+			result.Status = http.StatusNoContent
+		case outcome.Err == nil:
+			result.Status = http.StatusOK
+		case errors.Is(outcome.Err, service.ErrInvalidBatchOperation):
+			result.Status = http.StatusBadRequest
+			result.Error = outcome.Err.Error()
+		case errors.Is(outcome.Err, sql.ErrNoRows):
+			result.Status = http.StatusNotFound
+			result.Error = "User not found"
+		case errors.Is(outcome.Err, service.ErrUserErased):
+			result.Status = http.StatusConflict
+			result.Error = outcome.Err.Error()
+		case errors.Is(outcome.Err, service.ErrDuplicateEmail):
+			result.Status = http.StatusConflict
+			result.Error = "Email already in use"
+		case errors.Is(outcome.Err, service.ErrTimeout):
+			result.Status = http.StatusGatewayTimeout
+			result.Error = "Operation timed out"
+		case errors.Is(outcome.Err, service.ErrBatchAborted):
+			result.Status = http.StatusFailedDependency
+			result.Error = outcome.Err.Error()
+		default:
+			h.logger.WithError(outcome.Err).WithField("index", i).Error("Failed to apply batch operation")
+			result.Status = http.StatusInternalServerError
+			result.Error = "Failed to apply operation"
+		}
+		response.Results[i] = result
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// Export formats
+const (
+	exportCSV     = "csv"
+	exportNDJSON  = "ndjson"
+	exportParquet = "parquet"
+)
+
+// parquetRowGroupSize is how many users go into each Parquet row group. Row groups are
+// buffered in memory until they are complete, so this bounds the memory an export uses.
+const parquetRowGroupSize = 10000
+
+// exportColumns are the CSV columns of a user export
+var exportColumns = []string{"id", "email", "name", "role", "email_verified_at", "mfa_enabled_at", "erased_at", "created_at", "updated_at"}
+
+// userWriter writes the users of an export one at a time
+type userWriter interface {
+	Write(user *models.User) error
+	Flush() error
+}
+
+// ExportUsers streams every user as CSV, NDJSON or Parquet. CSV and NDJSON are gzipped
+// when the client accepts it; Parquet compresses its columns itself.
+func (h *UserHandler) ExportUsers(c *gin.Context) {
+	format := c.DefaultQuery("format", exportCSV)
+	contentType := ""
+	switch format {
+	case exportCSV:
+		contentType = "text/csv"
+	case exportNDJSON:
+		contentType = "application/x-ndjson"
+	case exportParquet:
+		contentType = "application/vnd.apache.parquet"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "format must be csv, ndjson or parquet",
+		})
+		return
+	}
+
+	// Headers are sent with the first user, so that a failure to start the export can
+	// still get an error response
+	var out io.Writer = c.Writer
+	var gz *gzip.Writer
+	var writer userWriter
+	start := func() error {
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", `attachment; filename="users.`+format+`"`)
+		if format != exportParquet {
+			c.Header("Vary", "Accept-Encoding")
+			if acceptsGzip(c.GetHeader("Accept-Encoding")) {
+				c.Header("Content-Encoding", "gzip")
+				gz = gzip.NewWriter(c.Writer)
+				out = gz
+			}
+		}
+		c.Status(http.StatusOK)
+
+		switch format {
+		case exportCSV:
+			writer = newCSVUserWriter(out)
+		case exportNDJSON:
+			writer = &ndjsonUserWriter{encoder: json.NewEncoder(out)}
+		default:
+			w, err := newParquetUserWriter(out)
+			if err != nil {
+				return err
+			}
+			writer = w
+		}
+		return nil
+	}
+
+	err := h.service.ExportUsers(c.Request.Context(), func(user *models.User) error {
+		if writer == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return writer.Write(user)
+	})
+	if err == nil && writer == nil {
+		err = start()
+	}
+	if err == nil {
+		if err = writer.Flush(); err == nil && gz != nil {
+			err = gz.Close()
+		}
+	}
+	if err == nil {
+		return
+	}
+
+	if writer == nil && h.queryAborted(c, err) {
+		return
+	}
+	h.logger.WithError(err).Error("Failed to export users")
+	if writer == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to export users",
+		})
+		return
+	}
+	// Drop the connection rather than end the response, so that clients do not take a
+	// partial export for a complete one
+	if conn, _, err := c.Writer.Hijack(); err == nil {
+		conn.Close()
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		if strings.TrimSpace(coding) != "gzip" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			weight, err := strconv.ParseFloat(q, 64)
+			return err == nil && weight > 0
+		}
+		return true
+	}
+	return false
+}
+
+// csvUserWriter writes users as CSV rows with a header row
+type csvUserWriter struct {
+	writer *csv.Writer
+	header bool
+}
+
+func newCSVUserWriter(w io.Writer) *csvUserWriter {
+	return &csvUserWriter{writer: csv.NewWriter(w)}
+}
+
+func (w *csvUserWriter) Write(user *models.User) error {
+	if !w.header {
+		w.header = true
+		if err := w.writer.Write(exportColumns); err != nil {
+			return err
+		}
+	}
+	return w.writer.Write([]string{
+		user.ID,
+		user.Email,
+		user.Name,
+		user.Role,
+		formatExportTime(user.EmailVerifiedAt),
+		formatExportTime(user.MFAEnabledAt),
+		formatExportTime(user.ErasedAt),
+		formatExportTime(&user.CreatedAt),
+		formatExportTime(&user.UpdatedAt),
+	})
+}
+
+func (w *csvUserWriter) Flush() error {
+	if !w.header {
+		w.header = true
+		w.writer.Write(exportColumns)
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// ndjsonUserWriter writes users as JSON objects, one per line
+type ndjsonUserWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonUserWriter) Write(user *models.User) error {
+	return w.encoder.Encode(user)
+}
+
+func (w *ndjsonUserWriter) Flush() error {
+	return nil
+}
+
+// parquetUser is the Parquet schema of a user export. Times are microseconds since the
+// Unix epoch in UTC.
+type parquetUser struct {
+	ID              string `parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Email           string `parquet:"name=email, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Name            string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Role            string `parquet:"name=role, type=BYTE_ARRAY, convertedtype=UTF8"`
+	EmailVerifiedAt *int64 `parquet:"name=email_verified_at, type=INT64, convertedtype=TIMESTAMP_MICROS, repetitiontype=OPTIONAL"`
+	MFAEnabledAt    *int64 `parquet:"name=mfa_enabled_at, type=INT64, convertedtype=TIMESTAMP_MICROS, repetitiontype=OPTIONAL"`
+	ErasedAt        *int64 `parquet:"name=erased_at, type=INT64, convertedtype=TIMESTAMP_MICROS, repetitiontype=OPTIONAL"`
+	CreatedAt       int64  `parquet:"name=created_at, type=INT64, convertedtype=TIMESTAMP_MICROS"`
+	UpdatedAt       int64  `parquet:"name=updated_at, type=INT64, convertedtype=TIMESTAMP_MICROS"`
+}
+
+// parquetUserWriter writes users as a Snappy-compressed Parquet file, writing out a row
+// group every parquetRowGroupSize users
+type parquetUserWriter struct {
+	writer *writer.ParquetWriter
+	rows   int
+}
+
+func newParquetUserWriter(w io.Writer) (*parquetUserWriter, error) {
+	pw, err := writer.NewParquetWriterFromWriter(w, new(parquetUser), 1)
+	if err != nil {
+		return nil, err
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+	return &parquetUserWriter{writer: pw}, nil
+}
+
+func (w *parquetUserWriter) Write(user *models.User) error {
+	err := w.writer.Write(parquetUser{
+		ID:              user.ID,
+		Email:           user.Email,
+		Name:            user.Name,
+		Role:            user.Role,
+		EmailVerifiedAt: parquetTime(user.EmailVerifiedAt),
+		MFAEnabledAt:    parquetTime(user.MFAEnabledAt),
+		ErasedAt:        parquetTime(user.ErasedAt),
+		CreatedAt:       user.CreatedAt.UnixMicro(),
+		UpdatedAt:       user.UpdatedAt.UnixMicro(),
+	})
+	if err != nil {
+		return err
+	}
+	w.rows++
+	if w.rows%parquetRowGroupSize == 0 {
+		return w.writer.Flush(true)
+	}
+	return nil
+}
+
+// Flush writes the last row group and the file footer
+func (w *parquetUserWriter) Flush() error {
+	return w.writer.WriteStop()
+}
+
+// parquetTime converts a time to Unix microseconds, or nil when it is nil
+func parquetTime(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	micros := t.UnixMicro()
+	return &micros
+}
+
+// formatExportTime formats a time as RFC 3339 in UTC, or as an empty string when it is nil
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
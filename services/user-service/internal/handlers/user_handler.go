@@ -1,32 +1,57 @@
 package handlers
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/middleware"
 	"github.com/yourusername/go-microservices/user-service/internal/models"
 	"github.com/yourusername/go-microservices/user-service/internal/service"
 )
 
+// UserService is the user logic UserHandler serves, implemented by service.UserService
+type UserService interface {
+	GetUsers(ctx context.Context) ([]models.User, error)
+	GetUser(ctx context.Context, id string) (*models.User, error)
+	GetUserAsOf(ctx context.Context, id string, asOf time.Time) (*models.User, error)
+	GetUserHistory(ctx context.Context, id string, limit, offset int) ([]models.UserVersion, error)
+	GetUsersByIDs(ctx context.Context, ids []string) (*models.BatchGetResponse, error)
+	ExportUsers(ctx context.Context, fn func(user *models.User) error) error
+	CreateUser(ctx context.Context, req *models.CreateUserRequest, actor models.Actor) (*models.User, error)
+	UpdateUser(ctx context.Context, id string, req *models.UpdateUserRequest, actor models.Actor) (*models.User, error)
+	DeleteUser(ctx context.Context, id string, actor models.Actor) error
+	ApplyBatch(ctx context.Context, ops []models.BatchOperation, atomic bool, actor models.Actor) []service.BatchOutcome
+}
+
 // UserHandler handles user-related requests
 type UserHandler struct {
-	service *service.UserService
+	service UserService
 	logger  *logrus.Logger
+	// batchMaxItems bounds the IDs of a batch get and the operations of a batch
+	batchMaxItems int
 }
 
 // NewUserHandler creates a new UserHandler
-func NewUserHandler(service *service.UserService, logger *logrus.Logger) *UserHandler {
+func NewUserHandler(service UserService, batchMaxItems int, logger *logrus.Logger) *UserHandler {
 	return &UserHandler{
-		service: service,
-		logger:  logger,
+		service:       service,
+		logger:        logger,
+		batchMaxItems: batchMaxItems,
 	}
 }
 
 // GetUsers gets all users
 func (h *UserHandler) GetUsers(c *gin.Context) {
-	users, err := h.service.GetUsers()
+	users, err := h.service.GetUsers(c.Request.Context())
 	if err != nil {
+		if h.queryAborted(c, err) {
+			return
+		}
 		h.logger.WithError(err).Error("Failed to get users")
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to get users",
@@ -37,12 +62,42 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 	c.JSON(http.StatusOK, users)
 }
 
-// GetUser gets a user by ID
+// GetUser gets a user by ID, or as they were at the RFC 3339 time in the as_of query
+// parameter
 func (h *UserHandler) GetUser(c *gin.Context) {
 	id := c.Param("id")
 	
-	user, err := h.service.GetUser(id)
+	var user *models.User
+	var err error
+	if v := c.Query("as_of"); v != "" {
+		// Past versions can hold addresses the user has since changed
+		if c.GetString(middleware.UserIDKey) == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Authentication required",
+			})
+			return
+		}
+		if !middleware.IsSelfOrAdmin(c, id) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Access denied",
+			})
+			return
+		}
+		asOf, parseErr := time.Parse(time.RFC3339, v)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid as_of",
+			})
+			return
+		}
+		user, err = h.service.GetUserAsOf(c.Request.Context(), id, asOf)
+	} else {
+		user, err = h.service.GetUser(c.Request.Context(), id)
+	}
 	if err != nil {
+		if h.queryAborted(c, err) {
+			return
+		}
 		h.logger.WithError(err).Error("Failed to get user")
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "User not found",
@@ -53,6 +108,28 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
+// GetUserHistory gets a page of a user's versions, newest first
+func (h *UserHandler) GetUserHistory(c *gin.Context) {
+	limit, offset, ok := pagination(c)
+	if !ok {
+		return
+	}
+	
+	versions, err := h.service.GetUserHistory(c.Request.Context(), c.Param("id"), limit, offset)
+	if err != nil {
+		if h.queryAborted(c, err) {
+			return
+		}
+		h.logger.WithError(err).Error("Failed to get user history")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get user history",
+		})
+		return
+	}
+	
+	c.JSON(http.StatusOK, versions)
+}
+
 // CreateUser creates a new user
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req models.CreateUserRequest
@@ -64,8 +141,17 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 	
-	user, err := h.service.CreateUser(&req)
+	user, err := h.service.CreateUser(c.Request.Context(), &req, actor(c))
 	if err != nil {
+		if h.queryAborted(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrDuplicateEmail) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Email already in use",
+			})
+			return
+		}
 		h.logger.WithError(err).Error("Failed to create user")
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to create user",
@@ -89,8 +175,23 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 	
-	user, err := h.service.UpdateUser(id, &req)
+	user, err := h.service.UpdateUser(c.Request.Context(), id, &req, actor(c))
 	if err != nil {
+		if h.queryAborted(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrUserErased) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrDuplicateEmail) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Email already in use",
+			})
+			return
+		}
 		h.logger.WithError(err).Error("Failed to update user")
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "User not found",
@@ -105,13 +206,53 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
 	
-	if err := h.service.DeleteUser(id); err != nil {
+	if err := h.service.DeleteUser(c.Request.Context(), id, actor(c)); err != nil {
+		if h.queryAborted(c, err) {
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
 		h.logger.WithError(err).Error("Failed to delete user")
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "User not found",
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete user",
 		})
 		return
 	}
 	
 	c.JSON(http.StatusNoContent, nil)
+}
+
+// actor identifies the caller for the audit log
+func actor(c *gin.Context) models.Actor {
+	return models.Actor{
+		UserID:    c.GetString(middleware.UserIDKey),
+		IP:        c.ClientIP(),
+		RequestID: c.GetString(middleware.RequestIDKey),
+	}
+}
+
+// StatusClientClosedRequest is the non-standard status logged for requests whose client
+// went away before they were answered
+const StatusClientClosedRequest = 499
+
+// queryAborted answers the request when err says its queries were canceled or timed out,
+// and reports whether it did
+func (h *UserHandler) queryAborted(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrTimeout):
+		h.logger.WithError(err).Warn("Request timed out")
+		c.JSON(http.StatusGatewayTimeout, gin.H{
+			"error": "Request timed out",
+		})
+		return true
+	case errors.Is(err, service.ErrCanceled):
+		h.logger.WithError(err).Info("Request canceled")
+		c.AbortWithStatus(StatusClientClosedRequest)
+		return true
+	}
+	return false
 }
\ No newline at end of file
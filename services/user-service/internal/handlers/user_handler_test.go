@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/auth"
+	"github.com/yourusername/go-microservices/user-service/internal/middleware"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+	"github.com/yourusername/go-microservices/user-service/internal/service"
+)
+
+// activeSessions treats every session as active
+type activeSessions struct{}
+
+func (activeSessions) IsSessionActive(id string) (bool, error) {
+	return true, nil
+}
+
+// newTestRouter routes user mutations the way the server does, over an in-memory store
+func newTestRouter() (*gin.Engine, *auth.TokenIssuer, *service.UserService) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(bytes.NewBuffer(nil))
+
+	issuer := auth.NewTokenIssuer("test-secret", time.Hour)
+	users := service.NewUserService(repository.NewMemoryUserStore(), service.NewVerificationService(nil, nil, time.Hour, "http://localhost"))
+	h := NewUserHandler(users, 100, logger)
+
+	router := gin.New()
+	authenticated := router.Group("/", middleware.Authenticate(issuer, activeSessions{}, logger))
+	authenticated.PUT("/users/:id", middleware.RequireSelfOrAdmin("id"), h.UpdateUser)
+	authenticated.DELETE("/users/:id", middleware.RequireAdmin(), h.DeleteUser)
+	return router, issuer, users
+}
+
+func createTestUser(t *testing.T, users *service.UserService, email, role string) *models.User {
+	user, err := users.CreateUser(context.Background(), &models.CreateUserRequest{Email: email, Name: "Test"}, models.Actor{})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	// Roles are not set through the API
+	user.Role = role
+	return user
+}
+
+func bearer(t *testing.T, issuer *auth.TokenIssuer, user *models.User) string {
+	token, err := issuer.Issue(user, "session-"+user.ID, time.Now())
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	return "Bearer " + token
+}
+
+func TestUserMutationsRequireAuthorization(t *testing.T) {
+	router, issuer, users := newTestRouter()
+	owner := createTestUser(t, users, "owner@example.com", models.RoleUser)
+	other := createTestUser(t, users, "other@example.com", models.RoleUser)
+	admin := createTestUser(t, users, "admin@example.com", models.RoleAdmin)
+
+	tests := []struct {
+		name   string
+		method string
+		caller *models.User
+		body   string
+		want   int
+	}{
+		{"update without a token", http.MethodPut, nil, `{"name": "Mallory"}`, http.StatusUnauthorized},
+		{"update by another user", http.MethodPut, other, `{"name": "Mallory"}`, http.StatusForbidden},
+		{"update by the user", http.MethodPut, owner, `{"name": "Owner"}`, http.StatusOK},
+		{"update by an admin", http.MethodPut, admin, `{"name": "Renamed"}`, http.StatusOK},
+		{"delete without a token", http.MethodDelete, nil, "", http.StatusUnauthorized},
+		{"delete by another user", http.MethodDelete, other, "", http.StatusForbidden},
+		{"delete by the user", http.MethodDelete, owner, "", http.StatusForbidden},
+		{"delete by an admin", http.MethodDelete, admin, "", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/users/"+owner.ID, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.caller != nil {
+				req.Header.Set("Authorization", bearer(t, issuer, tt.caller))
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
+
+func TestUpdateUserIgnoresRole(t *testing.T) {
+	router, issuer, users := newTestRouter()
+	owner := createTestUser(t, users, "owner@example.com", models.RoleUser)
+
+	req := httptest.NewRequest(http.MethodPut, "/users/"+owner.ID, bytes.NewBufferString(`{"name": "Owner", "role": "admin"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", bearer(t, issuer, owner))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	var updated models.User
+	if err := json.Unmarshal(rec.Body.Bytes(), &updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.Role != models.RoleUser {
+		t.Errorf("role = %q after a self-update asking for admin, want %q", updated.Role, models.RoleUser)
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/yourusername/go-microservices/user-service/internal/service"
+)
+
+// VerificationHandler handles email verification requests
+type VerificationHandler struct {
+	service *service.VerificationService
+	logger  *logrus.Logger
+}
+
+// NewVerificationHandler creates a new VerificationHandler
+func NewVerificationHandler(service *service.VerificationService, logger *logrus.Logger) *VerificationHandler {
+	return &VerificationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// SendVerification sends a new verification link to a user's current address
+func (h *VerificationHandler) SendVerification(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.service.Send(c.Request.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+		case errors.Is(err, service.ErrAlreadyVerified):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrTooManyRequests):
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			h.logger.WithError(err).Error("Failed to send verification email")
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to send verification email",
+			})
+		}
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// VerifyEmail consumes the verification token in the token query parameter
+func (h *VerificationHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing token",
+		})
+		return
+	}
+
+	user, err := h.service.Verify(token)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to verify email")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to verify email",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Readiness records whether the service can take traffic. It starts out not ready.
+type Readiness struct {
+	ready atomic.Bool
+}
+
+// SetReady marks the service as ready
+func (r *Readiness) SetReady() {
+	r.ready.Store(true)
+}
+
+// Ready reports whether the service is ready
+func (r *Readiness) Ready() bool {
+	return r.ready.Load()
+}
+
+// RequireReady returns a middleware that answers 503 until readiness is set. Routes in
+// skip, given as registered paths such as "/health", are served all along.
+func RequireReady(readiness *Readiness, skip ...string) gin.HandlerFunc {
+	skipped := make(map[string]bool, len(skip))
+	for _, path := range skip {
+		skipped[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if !readiness.Ready() && !skipped[c.FullPath()] {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error": "Service is starting",
+			})
+			return
+		}
+		c.Next()
+	}
+}
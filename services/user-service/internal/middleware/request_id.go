@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID that ties a request to its logs and audit entries
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the context key set by RequestID
+const RequestIDKey = "request_id"
+
+// maxRequestIDLength bounds the request IDs accepted from callers
+const maxRequestIDLength = 64
+
+// RequestID returns a middleware that keeps the caller's X-Request-ID, or assigns a new
+// one, and echoes it in the response
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.New().String()
+		}
+
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireServiceToken returns a middleware that rejects requests without the token shared
+// with the other services in the X-Service-Token header, for routes meant only for them
+func RequireServiceToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !validServiceToken(c, token) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid service token",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// validServiceToken reports whether the request carries the shared service token
+func validServiceToken(c *gin.Context, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Service-Token")), []byte(token)) == 1
+}
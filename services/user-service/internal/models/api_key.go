@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// API key scopes, checked by the gateway for requests authenticated with an API key
+const (
+	ScopeUsersRead     = "users:read"
+	ScopeUsersWrite    = "users:write"
+	ScopeProductsRead  = "products:read"
+	ScopeProductsWrite = "products:write"
+	ScopeInboxRead     = "inbox:read"
+	ScopeInboxWrite    = "inbox:write"
+)
+
+// APIKey is a long-lived credential for scripts and integrations. Only the hash of its
+// secret is stored; the prefix identifies it in listings and logs.
+type APIKey struct {
+	ID         string         `db:"id" json:"id"`
+	UserID     string         `db:"user_id" json:"user_id"`
+	Name       string         `db:"name" json:"name"`
+	Prefix     string         `db:"prefix" json:"prefix"`
+	SecretHash string         `db:"secret_hash" json:"-"`
+	Scopes     pq.StringArray `db:"scopes" json:"scopes"`
+	ExpiresAt  *time.Time     `db:"expires_at" json:"expires_at"`
+	LastUsedAt *time.Time     `db:"last_used_at" json:"last_used_at"`
+	RevokedAt  *time.Time     `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+// CreateAPIKeyRequest represents a request to issue an API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,oneof=users:read users:write products:read products:write inbox:read inbox:write"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreatedAPIKey is returned when an API key is issued. Key is shown only this once.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// IntrospectRequest represents a request to resolve a credential to an identity
+type IntrospectRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Introspection describes the identity behind an API key or an access token. Inactive
+// credentials carry no other fields.
+type Introspection struct {
+	Active    bool       `json:"active"`
+	UserID    string     `json:"user_id,omitempty"`
+	Role      string     `json:"role,omitempty"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	KeyID     string     `json:"key_id,omitempty"`
+	SessionID string     `json:"session_id,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditMFAEnrollmentStarted = "mfa.enrollment_started"
+	AuditMFAEnabled           = "mfa.enabled"
+	AuditMFAChallengeIssued   = "mfa.challenge_issued"
+	AuditMFAVerified          = "mfa.verified"
+	AuditMFARecoveryCodeUsed  = "mfa.recovery_code_used"
+	AuditMFAFailed            = "mfa.failed"
+	AuditMFAReset             = "mfa.reset"
+	AuditLoginUnlocked        = "login.unlocked"
+	AuditAPIKeyCreated        = "api_key.created"
+	AuditAPIKeyRevoked        = "api_key.revoked"
+	AuditSessionRevoked       = "session.revoked"
+	AuditUserCreated          = "user.created"
+	AuditUserUpdated          = "user.updated"
+	AuditUserDeleted          = "user.deleted"
+	AuditUserExported         = "user.exported"
+	AuditUserErased           = "user.erased"
+)
+
+// AuditEntry records a security-relevant action. ActorID is unset when the action was
+// taken by an unauthenticated caller. Hash is set by the database and chains the entry to
+// the one before it, whose hash is PrevHash. Erasing a user redacts the IP and Changes
+// of their entries and sets RedactedAt.
+type AuditEntry struct {
+	ID        int64         `db:"id" json:"id"`
+	ActorID   *string       `db:"actor_id" json:"actor_id"`
+	Action    string        `db:"action" json:"action"`
+	TargetID  *string       `db:"target_id" json:"target_id"`
+	IP        *string       `db:"ip" json:"ip"`
+	RequestID *string       `db:"request_id" json:"request_id"`
+	Metadata  AuditMetadata `db:"metadata" json:"metadata"`
+	Changes   AuditChanges  `db:"changes" json:"changes,omitempty"`
+	// DetailHash covers IP and Changes in the chain, so that they can be redacted
+	DetailHash string     `db:"detail_hash" json:"detail_hash"`
+	PrevHash   *string    `db:"prev_hash" json:"prev_hash"`
+	Hash       string     `db:"hash" json:"hash"`
+	RedactedAt *time.Time `db:"redacted_at" json:"redacted_at,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+// AuditFilter selects audit entries. Empty fields match every entry.
+type AuditFilter struct {
+	TargetID string
+	ActorID  string
+	Since    *time.Time
+}
+
+// AuditVerification is the result of checking the audit log hash chain. BrokenAt is the
+// first entry whose hash or link does not match. Head is the hash of the last entry;
+// keeping a copy elsewhere also detects entries removed from the end.
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int    `json:"entries"`
+	Head     string `json:"head"`
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+}
+
+// Actor identifies who made a request, for the audit log
+type Actor struct {
+	UserID    string
+	IP        string
+	RequestID string
+}
+
+// AuditMetadata holds action-specific details of an audit entry
+type AuditMetadata map[string]string
+
+// Value implements driver.Valuer
+func (m AuditMetadata) Value() (driver.Value, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements sql.Scanner
+func (m *AuditMetadata) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, m)
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	default:
+		return errors.New("unsupported type for AuditMetadata")
+	}
+}
+
+// AuditChange holds the values of a field before and after a change. Before is null for
+// created records and After is null for deleted ones.
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditChanges holds the changed fields of an audit entry's target, keyed by field name
+type AuditChanges map[string]AuditChange
+
+// Value implements driver.Valuer
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner
+func (c *AuditChanges) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	default:
+		return errors.New("unsupported type for AuditChanges")
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+import "encoding/json"
+
+// Batch operations
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchGetRequest represents a request for many users at once
+type BatchGetRequest struct {
+	IDs []string `json:"ids" binding:"required"`
+}
+
+// BatchGetResponse holds the users that were found, in the order they were asked for, and
+// the IDs of those that were not
+type BatchGetResponse struct {
+	Found   []User   `json:"found"`
+	Missing []string `json:"missing"`
+}
+
+// BatchOperation is one change in a batch. User holds a CreateUserRequest for creates and
+// an UpdateUserRequest for updates; ID names the user to update or delete.
+type BatchOperation struct {
+	Op   string          `json:"op"`
+	ID   string          `json:"id"`
+	User json.RawMessage `json:"user"`
+}
+
+// BatchRequest represents a request to apply several changes. With Atomic, either all
+// operations are applied or none are.
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations" binding:"required"`
+	Atomic     bool             `json:"atomic"`
+}
+
+// BatchResult is the outcome of one operation of a batch. Status is the HTTP status the
+// operation would have had as a request of its own.
+type BatchResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	Status int    `json:"status"`
+	User   *User  `json:"user,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchResponse holds the outcome of each operation of a batch, in order
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
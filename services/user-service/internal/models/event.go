@@ -0,0 +1,58 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// User lifecycle event types
+const (
+	EventUserCreated               = "user.created"
+	EventUserEmailChanged          = "user.email_changed"
+	EventUserVerificationRequested = "user.verification_requested"
+	EventPasswordResetRequested    = "user.password_reset_requested"
+	// EventUserErased asks subscribers to delete their copies of the user's personal data
+	EventUserErased = "user.erased"
+)
+
+// UserEvent is a user lifecycle event waiting in the outbox to be delivered to subscribers.
+// Events are written in the same transaction as the change they describe.
+type UserEvent struct {
+	ID            string     `db:"id" json:"id"`
+	Type          string     `db:"type" json:"type"`
+	UserID        string     `db:"user_id" json:"user_id"`
+	Data          EventData  `db:"data" json:"data"`
+	CreatedAt     time.Time  `db:"created_at" json:"occurred_at"`
+	Attempts      int        `db:"attempts" json:"-"`
+	NextAttemptAt time.Time  `db:"next_attempt_at" json:"-"`
+	LastError     *string    `db:"last_error" json:"-"`
+	DeliveredAt   *time.Time `db:"delivered_at" json:"-"`
+}
+
+// EventData is the user state carried by an event
+type EventData struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	OldEmail string `json:"old_email,omitempty"`
+	// Link carries a single-use secret and is removed from the outbox once delivered
+	Link string `json:"link,omitempty"`
+}
+
+// Value implements driver.Valuer
+func (d EventData) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+// Scan implements sql.Scanner
+func (d *EventData) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, d)
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	default:
+		return errors.New("unsupported type for EventData")
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+// Import job statuses
+const (
+	ImportPending   = "pending"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// Import file formats
+const (
+	ImportCSV    = "csv"
+	ImportNDJSON = "ndjson"
+)
+
+// ImportJob is a bulk import of users from an uploaded file. BytesRead out of SizeBytes
+// tracks progress through the file. A dry run validates rows without writing users.
+type ImportJob struct {
+	ID            string     `db:"id" json:"id"`
+	Status        string     `db:"status" json:"status"`
+	Format        string     `db:"format" json:"format"`
+	DryRun        bool       `db:"dry_run" json:"dry_run"`
+	SizeBytes     int64      `db:"size_bytes" json:"size_bytes"`
+	BytesRead     int64      `db:"bytes_read" json:"bytes_read"`
+	ProcessedRows int        `db:"processed_rows" json:"processed_rows"`
+	CreatedRows   int        `db:"created_rows" json:"created_rows"`
+	UpdatedRows   int        `db:"updated_rows" json:"updated_rows"`
+	FailedRows    int        `db:"failed_rows" json:"failed_rows"`
+	Error         *string    `db:"error" json:"error,omitempty"`
+	CreatedBy     *string    `db:"created_by" json:"created_by"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+	FinishedAt    *time.Time `db:"finished_at" json:"finished_at,omitempty"`
+}
+
+// ImportRowError explains why a row of an import file was rejected. Rows are numbered
+// from 1, not counting the CSV header.
+type ImportRowError struct {
+	JobID     string `db:"job_id" json:"-"`
+	RowNumber int    `db:"row_number" json:"row"`
+	Email     string `db:"email" json:"email"`
+	Error     string `db:"error" json:"error"`
+}
+
+// UserUpsert is a batch of imported users to create and update, with the records that
+// go with them
+type UserUpsert struct {
+	Create        []*User
+	Update        []*User
+	Verifications []*EmailVerificationToken
+	Audit         []*AuditEntry
+	Events        []*UserEvent
+}
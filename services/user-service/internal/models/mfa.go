@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// MFAChallenge links the two steps of a login with MFA. Only its hash is stored.
+type MFAChallenge struct {
+	TokenHash string     `db:"token_hash"`
+	UserID    string     `db:"user_id"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	Attempts  int        `db:"attempts"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// MFAEnrollment holds the TOTP secret of a pending enrollment
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// ConfirmMFARequest represents a request to confirm an enrollment with a first code
+type ConfirmMFARequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResponse holds the recovery codes generated on enrollment. They are
+// shown only once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAChallengeResponse is returned by login when the user has MFA enabled
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// VerifyMFARequest represents the second login step, with either a TOTP code or a
+// recovery code
+type VerifyMFARequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code"`
+	Device       string `json:"device" binding:"max=100"`
+}
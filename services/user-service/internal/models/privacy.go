@@ -0,0 +1,25 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// UserExport is everything held about a user, assembled for a data export request.
+// Notifications is the notification service's part of the export and is unset when the
+// notification service is not configured.
+type UserExport struct {
+	ExportedAt    time.Time       `json:"exported_at"`
+	Profile       *User           `json:"profile"`
+	History       []UserVersion   `json:"history"`
+	AuditLog      []AuditEntry    `json:"audit_log"`
+	Sessions      []Session       `json:"sessions"`
+	APIKeys       []APIKey        `json:"api_keys"`
+	Notifications json.RawMessage `json:"notifications"`
+}
+
+// Erasure is the outcome of an erasure request
+type Erasure struct {
+	UserID   string    `json:"user_id"`
+	ErasedAt time.Time `json:"erased_at"`
+}
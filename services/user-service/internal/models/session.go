@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// Session is a login on one device. It lasts as long as its chain of refresh tokens and
+// ends when it is revoked.
+type Session struct {
+	ID         string     `db:"id" json:"id"`
+	UserID     string     `db:"user_id" json:"user_id"`
+	Device     string     `db:"device" json:"device"`
+	IP         string     `db:"ip" json:"ip"`
+	UserAgent  string     `db:"user_agent" json:"user_agent"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastSeenAt time.Time  `db:"last_seen_at" json:"last_seen_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	// Current marks the session of the caller's access token
+	Current bool `db:"-" json:"current"`
+}
+
+// Client describes where a request comes from
+type Client struct {
+	IP        string
+	UserAgent string
+}
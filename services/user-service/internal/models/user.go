@@ -4,19 +4,36 @@ import (
 	"time"
 )
 
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // User represents a user in the system
 type User struct {
-	ID        string    `db:"id" json:"id"`
-	Email     string    `db:"email" json:"email"`
-	Name      string    `db:"name" json:"name"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	ID              string     `db:"id" json:"id"`
+	Email           string     `db:"email" json:"email"`
+	Name            string     `db:"name" json:"name"`
+	Role            string     `db:"role" json:"role"`
+	PasswordHash    *string    `db:"password_hash" json:"-"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at" json:"email_verified_at"`
+	// MFASecret is the TOTP secret; it is set at enrollment and in use once MFAEnabledAt is set
+	MFASecret    *string    `db:"mfa_secret" json:"-"`
+	MFAEnabledAt *time.Time `db:"mfa_enabled_at" json:"mfa_enabled_at"`
+	// MFALastStep is the time step of the last accepted code, so codes cannot be replayed
+	MFALastStep *int64 `db:"mfa_last_step" json:"-"`
+	// ErasedAt is set once the user's personal data has been erased
+	ErasedAt  *time.Time `db:"erased_at" json:"erased_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
 }
 
 // CreateUserRequest represents a request to create a user
 type CreateUserRequest struct {
-	Email string `json:"email" binding:"required,email"`
-	Name  string `json:"name" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Name     string `json:"name" binding:"required"`
+	Password string `json:"password" binding:"omitempty,min=8,max=72"`
 }
 
 // UpdateUserRequest represents a request to update a user
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// User history operations
+const (
+	HistoryInsert = "insert"
+	HistoryUpdate = "update"
+	HistoryDelete = "delete"
+)
+
+// UserVersion is a user's profile as it was from ValidFrom until ValidTo, which is unset
+// for the latest version. ChangedFields lists the fields an update changed.
+type UserVersion struct {
+	ID              int64          `db:"id" json:"version"`
+	UserID          string         `db:"user_id" json:"user_id"`
+	Operation       string         `db:"operation" json:"operation"`
+	ChangedFields   pq.StringArray `db:"changed_fields" json:"changed_fields"`
+	Email           string         `db:"email" json:"email"`
+	Name            string         `db:"name" json:"name"`
+	Role            string         `db:"role" json:"role"`
+	EmailVerifiedAt *time.Time     `db:"email_verified_at" json:"email_verified_at"`
+	MFAEnabledAt    *time.Time     `db:"mfa_enabled_at" json:"mfa_enabled_at"`
+	CreatedAt       time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time      `db:"updated_at" json:"updated_at"`
+	ValidFrom       time.Time      `db:"valid_from" json:"valid_from"`
+	ValidTo         *time.Time     `db:"valid_to" json:"valid_to"`
+}
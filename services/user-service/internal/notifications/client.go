@@ -0,0 +1,55 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Client calls the notification service
+type Client struct {
+	baseURL      string
+	serviceToken string
+	client       *http.Client
+}
+
+// NewClient creates a new Client for the notification service at baseURL, which only
+// answers requests carrying serviceToken
+func NewClient(baseURL, serviceToken string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL:      baseURL,
+		serviceToken: serviceToken,
+		client:       &http.Client{Timeout: timeout},
+	}
+}
+
+// GetUserData gets everything the notification service holds about a user, as JSON
+func (c *Client) GetUserData(userID string) (json.RawMessage, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/users/"+url.PathEscape(userID)+"/data", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification data: %w", err)
+	}
+	req.Header.Set("X-Service-Token", c.serviceToken)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get notification data: status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification data: %w", err)
+	}
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("failed to get notification data: invalid JSON")
+	}
+	return body, nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// APIKeyRepository handles database operations for API keys
+type APIKeyRepository struct {
+	db *sqlx.DB
+}
+
+// NewAPIKeyRepository creates a new APIKeyRepository
+func NewAPIKeyRepository(db *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+// CreateAPIKey stores a new API key
+func (r *APIKeyRepository) CreateAPIKey(key *models.APIKey, audit *models.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO api_keys (id, user_id, name, prefix, secret_hash, scopes, expires_at, created_at)
+		VALUES (:id, :user_id, :name, :prefix, :secret_hash, :scopes, :expires_at, :created_at)
+	`
+	if _, err := tx.NamedExec(query, key); err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	if err := insertAuditEntries(tx, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	return nil
+}
+
+// GetAPIKeys gets a user's API keys, newest first, including revoked and expired ones
+func (r *APIKeyRepository) GetAPIKeys(userID string) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	query := `SELECT * FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`
+	if err := r.db.Select(&keys, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// GetActiveAPIKey gets an unrevoked, unexpired API key by its prefix together with the
+// role of its owner
+func (r *APIKeyRepository) GetActiveAPIKey(prefix string) (*models.APIKey, string, error) {
+	var row struct {
+		models.APIKey
+		Role string `db:"role"`
+	}
+	query := `
+		SELECT k.*, u.role FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.prefix = $1 AND k.revoked_at IS NULL AND (k.expires_at IS NULL OR k.expires_at > NOW())
+	`
+	if err := r.db.Get(&row, query, prefix); err != nil {
+		return nil, "", fmt.Errorf("failed to get API key: %w", err)
+	}
+	return &row.APIKey, row.Role, nil
+}
+
+// TouchAPIKey records that an API key was used. The timestamp is kept to the minute to
+// avoid a write on every request.
+func (r *APIKeyRepository) TouchAPIKey(id string) error {
+	query := `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+	if _, err := r.db.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to update API key: %w", err)
+	}
+	return nil
+}
+
+// RevokeAPIKey revokes one of a user's API keys. It returns sql.ErrNoRows when the user
+// has no such active key.
+func (r *APIKeyRepository) RevokeAPIKey(userID, id string, audit *models.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	if err := execOne(tx, query, id, userID); err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if err := insertAuditEntries(tx, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// AuditRepository handles database operations for the audit log. Entries are written
+// by the repositories of the changes they describe; the table itself rejects updates
+// and deletes.
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+// NewAuditRepository creates a new AuditRepository
+func NewAuditRepository(db *sqlx.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+// GetAuditEntries gets a page of the entries matching filter, newest first
+func (r *AuditRepository) GetAuditEntries(filter *models.AuditFilter, limit, offset int) ([]models.AuditEntry, error) {
+	entries := []models.AuditEntry{}
+	query := `
+		SELECT * FROM audit_log
+		WHERE ($1 = '' OR target_id::TEXT = $1)
+			AND ($2 = '' OR actor_id::TEXT = $2)
+			AND ($3::TIMESTAMPTZ IS NULL OR created_at >= $3)
+		ORDER BY id DESC
+		LIMIT $4 OFFSET $5
+	`
+	err := r.db.Select(&entries, query, filter.TargetID, filter.ActorID, filter.Since, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// VerifyChain recomputes the hash of every entry in order and checks that each entry
+// links to the one before it. The details of entries that were not redacted must also
+// match their hash.
+func (r *AuditRepository) VerifyChain() (*models.AuditVerification, error) {
+	rows, err := r.db.Queryx(`
+		SELECT id, COALESCE(prev_hash, '') AS prev_hash, hash, audit_log_hash(prev_hash, a) AS expected,
+			redacted_at IS NOT NULL OR detail_hash = audit_log_detail_hash(a) AS details_match
+		FROM audit_log a
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify audit log: %w", err)
+	}
+	defer rows.Close()
+
+	result := &models.AuditVerification{Valid: true}
+	for rows.Next() {
+		var row struct {
+			ID       int64  `db:"id"`
+			PrevHash string `db:"prev_hash"`
+			Hash     string `db:"hash"`
+			Expected string `db:"expected"`
+			Details  bool   `db:"details_match"`
+		}
+		if err := rows.StructScan(&row); err != nil {
+			return nil, fmt.Errorf("failed to verify audit log: %w", err)
+		}
+
+		if result.Valid && (row.PrevHash != result.Head || row.Hash != row.Expected || !row.Details) {
+			result.Valid = false
+			result.BrokenAt = &row.ID
+		}
+		result.Entries++
+		result.Head = row.Hash
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to verify audit log: %w", err)
+	}
+	return result, nil
+}
+
+// insertAuditEntries appends entries to the audit log as part of tx, so that they are
+// only recorded when the change they describe is
+func insertAuditEntries(tx *sqlx.Tx, entries ...*models.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor_id, action, target_id, ip, request_id, metadata, changes, created_at)
+		VALUES (:actor_id, :action, :target_id, :ip, :request_id, :metadata, :changes, :created_at)
+	`
+	for _, entry := range entries {
+		if _, err := tx.NamedExec(query, entry); err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+	}
+	return nil
+}
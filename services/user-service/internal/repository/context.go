@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// ErrCanceled is returned when the caller gave up on a query, usually because the
+	// client went away or the service is shutting down
+	ErrCanceled = errors.New("query canceled")
+	// ErrTimeout is returned when a query ran past the caller's deadline or the
+	// database's statement timeout
+	ErrTimeout = errors.New("query timed out")
+)
+
+var queriesAbortedTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "user_db_queries_aborted_total",
+		Help: "Total number of database operations abandoned because they were canceled or timed out",
+	},
+	[]string{"reason"},
+)
+
+// contextError marks err with ErrCanceled or ErrTimeout when it was caused by the end of
+// ctx or by a statement timeout, and counts it. Other errors are returned unchanged.
+func contextError(ctx context.Context, err error) error {
+	if err == nil || errors.Is(err, ErrCanceled) || errors.Is(err, ErrTimeout) {
+		return err
+	}
+
+	var pqErr *pq.Error
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		queriesAbortedTotal.WithLabelValues("timeout").Inc()
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	case errors.Is(ctx.Err(), context.Canceled):
+		queriesAbortedTotal.WithLabelValues("canceled").Inc()
+		return fmt.Errorf("%w: %w", ErrCanceled, err)
+	case errors.As(err, &pqErr) && pqErr.Code == "57014":
+		// query_canceled without the context ending is the statement_timeout setting
+		queriesAbortedTotal.WithLabelValues("timeout").Inc()
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// EventRepository handles database operations for the user event outbox
+type EventRepository struct {
+	db *sqlx.DB
+}
+
+// NewEventRepository creates a new EventRepository
+func NewEventRepository(db *sqlx.DB) *EventRepository {
+	return &EventRepository{
+		db: db,
+	}
+}
+
+// ClaimPending leases up to limit undelivered events that are due, oldest first, by
+// moving their next attempt lease into the future. The claim commits immediately, so no
+// locks are held while the events are delivered, and other replicas skip them until the
+// lease runs out. Each returned event's NextAttemptAt identifies the claim.
+func (r *EventRepository) ClaimPending(limit int, lease time.Duration) ([]models.UserEvent, error) {
+	events := []models.UserEvent{}
+	query := `
+		WITH due AS (
+			SELECT id FROM user_events
+			WHERE delivered_at IS NULL AND next_attempt_at <= NOW()
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE user_events e
+		SET next_attempt_at = NOW() + $2::bigint * INTERVAL '1 millisecond'
+		FROM due
+		WHERE e.id = due.id
+		RETURNING e.*
+	`
+	if err := r.db.Select(&events, query, limit, lease.Milliseconds()); err != nil {
+		return nil, fmt.Errorf("failed to claim pending events: %w", err)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+	return events, nil
+}
+
+// MarkDelivered records the delivery of a claimed event. It applies even if the lease has
+// run out meanwhile, since the event did reach the subscriber.
+func (r *EventRepository) MarkDelivered(event *models.UserEvent) error {
+	// Links carry single-use secrets, so they do not outlive delivery
+	query := `
+		UPDATE user_events
+		SET attempts = attempts + 1, last_error = NULL, delivered_at = NOW(), data = data - 'link'
+		WHERE id = $1 AND delivered_at IS NULL
+	`
+	if _, err := r.db.Exec(query, event.ID); err != nil {
+		return fmt.Errorf("failed to record event delivery: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed delivery of a claimed event and schedules the next attempt
+// at next. It does nothing if another replica has claimed the event since.
+func (r *EventRepository) MarkFailed(event *models.UserEvent, errMsg string, next time.Time) error {
+	query := `
+		UPDATE user_events
+		SET attempts = attempts + 1, last_error = $3, next_attempt_at = $4
+		WHERE id = $1 AND next_attempt_at = $2 AND delivered_at IS NULL
+	`
+	if _, err := r.db.Exec(query, event.ID, event.NextAttemptAt, errMsg, next); err != nil {
+		return fmt.Errorf("failed to record event failure: %w", err)
+	}
+	return nil
+}
+
+// insertEvents adds events to the outbox as part of a transaction
+func insertEvents(tx *sqlx.Tx, events []*models.UserEvent) error {
+	query := `
+		INSERT INTO user_events (id, type, user_id, data, created_at, next_attempt_at)
+		VALUES (:id, :type, :user_id, :data, :created_at, :created_at)
+	`
+	for _, event := range events {
+		if _, err := tx.NamedExec(query, event); err != nil {
+			return fmt.Errorf("failed to record %s event: %w", event.Type, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// ImportRepository handles database operations for bulk user imports
+type ImportRepository struct {
+	db *sqlx.DB
+}
+
+// NewImportRepository creates a new ImportRepository
+func NewImportRepository(db *sqlx.DB) *ImportRepository {
+	return &ImportRepository{
+		db: db,
+	}
+}
+
+// CreateImportJob creates an import job
+func (r *ImportRepository) CreateImportJob(job *models.ImportJob) error {
+	query := `
+		INSERT INTO import_jobs (id, status, format, dry_run, size_bytes, created_by, created_at, updated_at)
+		VALUES (:id, :status, :format, :dry_run, :size_bytes, :created_by, :created_at, :updated_at)
+	`
+	if _, err := r.db.NamedExec(query, job); err != nil {
+		return fmt.Errorf("failed to create import job: %w", err)
+	}
+	return nil
+}
+
+// GetImportJob gets an import job by ID
+func (r *ImportRepository) GetImportJob(id string) (*models.ImportJob, error) {
+	var job models.ImportJob
+	query := `SELECT * FROM import_jobs WHERE id = $1`
+	if err := r.db.Get(&job, query, id); err != nil {
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+	return &job, nil
+}
+
+// UpdateImportJob saves the status and progress of an import job together with the
+// errors of the rows processed since the last update
+func (r *ImportRepository) UpdateImportJob(job *models.ImportJob, rowErrors []models.ImportRowError) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to update import job: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE import_jobs
+		SET status = :status, bytes_read = :bytes_read, processed_rows = :processed_rows,
+			created_rows = :created_rows, updated_rows = :updated_rows, failed_rows = :failed_rows,
+			error = :error, updated_at = :updated_at, finished_at = :finished_at
+		WHERE id = :id
+	`
+	if _, err := tx.NamedExec(query, job); err != nil {
+		return fmt.Errorf("failed to update import job: %w", err)
+	}
+	if len(rowErrors) > 0 {
+		query := `
+			INSERT INTO import_job_errors (job_id, row_number, email, error)
+			VALUES (:job_id, :row_number, :email, :error)
+		`
+		if _, err := tx.NamedExec(query, rowErrors); err != nil {
+			return fmt.Errorf("failed to record import errors: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to update import job: %w", err)
+	}
+	return nil
+}
+
+// GetImportErrors gets the row errors of an import job in row order
+func (r *ImportRepository) GetImportErrors(jobID string) ([]models.ImportRowError, error) {
+	rowErrors := []models.ImportRowError{}
+	query := `SELECT * FROM import_job_errors WHERE job_id = $1 ORDER BY row_number`
+	if err := r.db.Select(&rowErrors, query, jobID); err != nil {
+		return nil, fmt.Errorf("failed to get import errors: %w", err)
+	}
+	return rowErrors, nil
+}
+
+// UpsertUsers creates and updates a batch of imported users in one transaction. The
+// users with the given addresses are locked and passed to plan, which decides what to
+// write; users is keyed by email.
+func (r *ImportRepository) UpsertUsers(emails []string, plan func(users map[string]*models.User) (*models.UserUpsert, error)) (*models.UserUpsert, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to import users: %w", err)
+	}
+	defer tx.Rollback()
+
+	query, args, err := sqlx.In(`SELECT * FROM users WHERE email IN (?) ORDER BY id FOR UPDATE`, emails)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import users: %w", err)
+	}
+	var existing []models.User
+	if err := tx.Select(&existing, tx.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to import users: %w", err)
+	}
+	users := make(map[string]*models.User, len(existing))
+	for i := range existing {
+		users[existing[i].Email] = &existing[i]
+	}
+
+	upsert, err := plan(users)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(upsert.Create) > 0 {
+		query := `
+			INSERT INTO users (id, email, name, role, password_hash, email_verified_at, created_at, updated_at)
+			VALUES (:id, :email, :name, :role, :password_hash, :email_verified_at, :created_at, :updated_at)
+		`
+		if _, err := tx.NamedExec(query, upsert.Create); err != nil {
+			return nil, fmt.Errorf("failed to create users: %w", err)
+		}
+	}
+	for _, user := range upsert.Update {
+		query := `UPDATE users SET name = :name, updated_at = :updated_at WHERE id = :id`
+		if _, err := tx.NamedExec(query, user); err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+	}
+	if len(upsert.Verifications) > 0 {
+		query := `
+			INSERT INTO email_verification_tokens (token_hash, user_id, email, expires_at, created_at)
+			VALUES (:token_hash, :user_id, :email, :expires_at, :created_at)
+		`
+		if _, err := tx.NamedExec(query, upsert.Verifications); err != nil {
+			return nil, fmt.Errorf("failed to create verification tokens: %w", err)
+		}
+	}
+	if err := insertAuditEntries(tx, upsert.Audit...); err != nil {
+		return nil, err
+	}
+	if err := insertEvents(tx, upsert.Events); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to import users: %w", err)
+	}
+	return upsert, nil
+}
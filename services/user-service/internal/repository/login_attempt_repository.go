@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// LoginAttemptRepository handles database operations for failed login tracking
+type LoginAttemptRepository struct {
+	db *sqlx.DB
+}
+
+// NewLoginAttemptRepository creates a new LoginAttemptRepository
+func NewLoginAttemptRepository(db *sqlx.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		db: db,
+	}
+}
+
+// LockedUntil returns the latest time until which any of the given keys of a scope
+// are locked, or the zero time when none of them is
+func (r *LoginAttemptRepository) LockedUntil(keys map[string]string, now time.Time) (time.Time, error) {
+	var until time.Time
+	for scope, key := range keys {
+		var locked []time.Time
+		query := `SELECT locked_until FROM login_attempts WHERE scope = $1 AND key = $2 AND locked_until > $3`
+		if err := r.db.Select(&locked, query, scope, key, now); err != nil {
+			return time.Time{}, fmt.Errorf("failed to get login lockout: %w", err)
+		}
+		for _, t := range locked {
+			if t.After(until) {
+				until = t
+			}
+		}
+	}
+	return until, nil
+}
+
+// RecordFailure counts a failed login against a key. Failures older than windowStart are
+// forgotten first. lockFor is called with the new failure count and returns how long the
+// key is locked for, if at all. It returns the new failure count.
+func (r *LoginAttemptRepository) RecordFailure(scope, key string, now, windowStart time.Time, lockFor func(failures int) time.Duration) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	defer tx.Rollback()
+
+	prune := `DELETE FROM login_attempts WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until < $2)`
+	if _, err := tx.Exec(prune, windowStart, now); err != nil {
+		return 0, fmt.Errorf("failed to prune login failures: %w", err)
+	}
+
+	var failures int
+	query := `
+		INSERT INTO login_attempts (scope, key, failures, last_failure_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (scope, key) DO UPDATE
+		SET failures = login_attempts.failures + 1, last_failure_at = EXCLUDED.last_failure_at
+		RETURNING failures
+	`
+	if err := tx.Get(&failures, query, scope, key, now); err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	if d := lockFor(failures); d > 0 {
+		lock := `UPDATE login_attempts SET locked_until = $3 WHERE scope = $1 AND key = $2`
+		if _, err := tx.Exec(lock, scope, key, now.Add(d)); err != nil {
+			return 0, fmt.Errorf("failed to lock login: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return failures, nil
+}
+
+// Reset forgets the failures and lockouts of the given keys of a scope
+func (r *LoginAttemptRepository) Reset(keys map[string]string) error {
+	for scope, key := range keys {
+		if _, err := r.db.Exec(`DELETE FROM login_attempts WHERE scope = $1 AND key = $2`, scope, key); err != nil {
+			return fmt.Errorf("failed to reset login failures: %w", err)
+		}
+	}
+	return nil
+}
+
+// Unlock forgets the failures and lockout of an account on behalf of an admin
+func (r *LoginAttemptRepository) Unlock(scope, key string, audit *models.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to unlock login: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM login_attempts WHERE scope = $1 AND key = $2`, scope, key); err != nil {
+		return fmt.Errorf("failed to unlock login: %w", err)
+	}
+	if err := insertAuditEntries(tx, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to unlock login: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,408 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// MemoryUserStore is a UserStore that keeps everything in memory, for tests. It keeps a
+// history of profile versions as the users_history trigger does, and the verification
+// tokens, audit entries and events written with each change. It is safe for concurrent
+// use; transactions run one at a time. Operations check their context before they start,
+// as queries would fail once it has ended.
+type MemoryUserStore struct {
+	mu    sync.Mutex
+	state *memoryUserState
+}
+
+// NewMemoryUserStore creates an empty MemoryUserStore
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{
+		state: &memoryUserState{users: map[string]models.User{}},
+	}
+}
+
+// memoryUserState is everything a MemoryUserStore holds
+type memoryUserState struct {
+	users         map[string]models.User
+	history       []models.UserVersion
+	verifications []models.EmailVerificationToken
+	audit         []models.AuditEntry
+	events        []models.UserEvent
+}
+
+// clone copies the state for a transaction to work on
+func (s *memoryUserState) clone() *memoryUserState {
+	users := make(map[string]models.User, len(s.users))
+	for id, user := range s.users {
+		users[id] = user
+	}
+	return &memoryUserState{
+		users:         users,
+		history:       append([]models.UserVersion(nil), s.history...),
+		verifications: append([]models.EmailVerificationToken(nil), s.verifications...),
+		audit:         append([]models.AuditEntry(nil), s.audit...),
+		events:        append([]models.UserEvent(nil), s.events...),
+	}
+}
+
+// GetUsers gets all users, oldest first
+func (s *MemoryUserStore) GetUsers(ctx context.Context) ([]models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", contextError(ctx, err))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.sortedUsers(), nil
+}
+
+// GetUserByID gets a user by ID
+func (s *MemoryUserStore) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", contextError(ctx, err))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.getUser(id)
+}
+
+// GetUsersByIDs gets the users with the given IDs, in no particular order
+func (s *MemoryUserStore) GetUsersByIDs(ctx context.Context, ids []string) ([]models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", contextError(ctx, err))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := []models.User{}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if user, ok := s.state.users[id]; ok && !seen[id] {
+			seen[id] = true
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// GetUserAsOf gets a user as they were at asOf. It returns sql.ErrNoRows when the user
+// did not exist then.
+func (s *MemoryUserStore) GetUserAsOf(ctx context.Context, id string, asOf time.Time) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", contextError(ctx, err))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.state.history) - 1; i >= 0; i-- {
+		v := s.state.history[i]
+		if v.UserID != id || v.ValidFrom.After(asOf) || (v.ValidTo != nil && !v.ValidTo.After(asOf)) {
+			continue
+		}
+		if v.Operation == models.HistoryDelete {
+			break
+		}
+		return &models.User{
+			ID:              v.UserID,
+			Email:           v.Email,
+			Name:            v.Name,
+			Role:            v.Role,
+			EmailVerifiedAt: v.EmailVerifiedAt,
+			MFAEnabledAt:    v.MFAEnabledAt,
+			CreatedAt:       v.CreatedAt,
+			UpdatedAt:       v.UpdatedAt,
+		}, nil
+	}
+	return nil, fmt.Errorf("failed to get user: %w", sql.ErrNoRows)
+}
+
+// GetUserHistory gets a page of a user's versions, newest first
+func (s *MemoryUserStore) GetUserHistory(ctx context.Context, id string, limit, offset int) ([]models.UserVersion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get user history: %w", contextError(ctx, err))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := []models.UserVersion{}
+	for i := len(s.state.history) - 1; i >= 0 && len(versions) < limit; i-- {
+		if s.state.history[i].UserID != id {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		versions = append(versions, s.state.history[i])
+	}
+	return versions, nil
+}
+
+// ExportUsers passes every user to fn, oldest first, stopping at the first error fn
+// returns or when ctx ends. Users come from a copy taken at the start, as from a snapshot.
+func (s *MemoryUserStore) ExportUsers(ctx context.Context, fn func(user *models.User) error) error {
+	s.mu.Lock()
+	users := s.state.sortedUsers()
+	s.mu.Unlock()
+
+	for i := range users {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("failed to export users: %w", contextError(ctx, err))
+		}
+		if err := fn(&users[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateUser creates a new user with the given verification token, audit entry and events
+func (s *MemoryUserStore) CreateUser(ctx context.Context, user *models.User, verification *models.EmailVerificationToken, audit *models.AuditEntry, events ...*models.UserEvent) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to create user: %w", contextError(ctx, err))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.createUser(user, verification, audit, events)
+}
+
+// UpdateUser updates a user's email address, name and verification time with the given
+// verification token, audit entry and events
+func (s *MemoryUserStore) UpdateUser(ctx context.Context, user *models.User, verification *models.EmailVerificationToken, audit *models.AuditEntry, events ...*models.UserEvent) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to update user: %w", contextError(ctx, err))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.updateUser(user, verification, audit, events)
+}
+
+// DeleteUser deletes a user and records the audit entry. It returns sql.ErrNoRows when
+// the user does not exist.
+func (s *MemoryUserStore) DeleteUser(ctx context.Context, id string, audit *models.AuditEntry) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to delete user: %w", contextError(ctx, err))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.deleteUser(id, audit)
+}
+
+// InTx runs fn on a copy of the store, which replaces the store when fn returns nil and
+// ctx has not ended
+func (s *MemoryUserStore) InTx(ctx context.Context, fn func(tx UserWriter) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.state.clone()
+	if err := fn(&memoryUserTx{state: state}); err != nil {
+		return contextError(ctx, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to apply changes: %w", contextError(ctx, err))
+	}
+	s.state = state
+	return nil
+}
+
+// AuditEntries returns the audit entries recorded so far, oldest first
+func (s *MemoryUserStore) AuditEntries() []models.AuditEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.AuditEntry(nil), s.state.audit...)
+}
+
+// Events returns the events recorded so far, oldest first
+func (s *MemoryUserStore) Events() []models.UserEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.UserEvent(nil), s.state.events...)
+}
+
+// memoryUserTx is the UserWriter of a MemoryUserStore transaction. The store is locked
+// for as long as the transaction runs.
+type memoryUserTx struct {
+	state *memoryUserState
+}
+
+func (t *memoryUserTx) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", contextError(ctx, err))
+	}
+	return t.state.getUser(id)
+}
+
+func (t *memoryUserTx) CreateUser(ctx context.Context, user *models.User, verification *models.EmailVerificationToken, audit *models.AuditEntry, events ...*models.UserEvent) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to create user: %w", contextError(ctx, err))
+	}
+	return t.state.createUser(user, verification, audit, events)
+}
+
+func (t *memoryUserTx) UpdateUser(ctx context.Context, user *models.User, verification *models.EmailVerificationToken, audit *models.AuditEntry, events ...*models.UserEvent) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to update user: %w", contextError(ctx, err))
+	}
+	return t.state.updateUser(user, verification, audit, events)
+}
+
+func (t *memoryUserTx) DeleteUser(ctx context.Context, id string, audit *models.AuditEntry) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to delete user: %w", contextError(ctx, err))
+	}
+	return t.state.deleteUser(id, audit)
+}
+
+func (s *memoryUserState) getUser(id string) (*models.User, error) {
+	user, ok := s.users[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get user: %w", sql.ErrNoRows)
+	}
+	return &user, nil
+}
+
+func (s *memoryUserState) sortedUsers() []models.User {
+	users := make([]models.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.Before(users[j].CreatedAt)
+		}
+		return users[i].ID < users[j].ID
+	})
+	return users
+}
+
+// emailTaken reports whether a user other than id has email
+func (s *memoryUserState) emailTaken(email, id string) bool {
+	for _, user := range s.users {
+		if user.Email == email && user.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *memoryUserState) createUser(user *models.User, verification *models.EmailVerificationToken, audit *models.AuditEntry, events []*models.UserEvent) error {
+	if _, ok := s.users[user.ID]; ok {
+		return fmt.Errorf("failed to create user: %w", errors.New("duplicate id"))
+	}
+	if s.emailTaken(user.Email, "") {
+		return fmt.Errorf("failed to create user: %w", ErrDuplicateEmail)
+	}
+
+	// Only the columns the Postgres insert sets are kept; the rest take their defaults
+	stored := models.User{
+		ID:              user.ID,
+		Email:           user.Email,
+		Name:            user.Name,
+		Role:            models.RoleUser,
+		PasswordHash:    user.PasswordHash,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+	s.users[user.ID] = stored
+	s.recordVersion(models.HistoryInsert, nil, stored)
+	s.record(verification, audit, events)
+	return nil
+}
+
+func (s *memoryUserState) updateUser(user *models.User, verification *models.EmailVerificationToken, audit *models.AuditEntry, events []*models.UserEvent) error {
+	stored, ok := s.users[user.ID]
+	if !ok {
+		return fmt.Errorf("failed to update user: %w", sql.ErrNoRows)
+	}
+	if s.emailTaken(user.Email, user.ID) {
+		return fmt.Errorf("failed to update user: %w", ErrDuplicateEmail)
+	}
+
+	before := stored
+	stored.Email = user.Email
+	stored.Name = user.Name
+	stored.EmailVerifiedAt = user.EmailVerifiedAt
+	stored.UpdatedAt = user.UpdatedAt
+	s.users[user.ID] = stored
+
+	var changed []string
+	if before.Email != stored.Email {
+		changed = append(changed, "email")
+	}
+	if before.Name != stored.Name {
+		changed = append(changed, "name")
+	}
+	if !equalTimes(before.EmailVerifiedAt, stored.EmailVerifiedAt) {
+		changed = append(changed, "email_verified_at")
+	}
+	if len(changed) > 0 {
+		s.recordVersion(models.HistoryUpdate, changed, stored)
+	}
+	s.record(verification, audit, events)
+	return nil
+}
+
+func (s *memoryUserState) deleteUser(id string, audit *models.AuditEntry) error {
+	stored, ok := s.users[id]
+	if !ok {
+		return fmt.Errorf("failed to delete user: %w", sql.ErrNoRows)
+	}
+	delete(s.users, id)
+	s.recordVersion(models.HistoryDelete, nil, stored)
+	s.record(nil, audit, nil)
+	return nil
+}
+
+// recordVersion closes the current version of a user and adds the next one
+func (s *memoryUserState) recordVersion(operation string, changed []string, user models.User) {
+	now := time.Now()
+	for i := range s.history {
+		if s.history[i].UserID == user.ID && s.history[i].ValidTo == nil {
+			s.history[i].ValidTo = &now
+		}
+	}
+	s.history = append(s.history, models.UserVersion{
+		ID:              int64(len(s.history) + 1),
+		UserID:          user.ID,
+		Operation:       operation,
+		ChangedFields:   append([]string{}, changed...),
+		Email:           user.Email,
+		Name:            user.Name,
+		Role:            user.Role,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		MFAEnabledAt:    user.MFAEnabledAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+		ValidFrom:       now,
+	})
+}
+
+// record keeps the verification token, audit entry and events written with a change
+func (s *memoryUserState) record(verification *models.EmailVerificationToken, audit *models.AuditEntry, events []*models.UserEvent) {
+	if verification != nil {
+		s.verifications = append(s.verifications, *verification)
+	}
+	if audit != nil {
+		entry := *audit
+		entry.ID = int64(len(s.audit) + 1)
+		s.audit = append(s.audit, entry)
+	}
+	for _, event := range events {
+		s.events = append(s.events, *event)
+	}
+}
+
+func equalTimes(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
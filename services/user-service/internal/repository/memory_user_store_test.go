@@ -0,0 +1,14 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+	"github.com/yourusername/go-microservices/user-service/internal/repository/storetest"
+)
+
+func TestMemoryUserStore(t *testing.T) {
+	storetest.TestUserStore(t, func(t *testing.T) repository.UserStore {
+		return repository.NewMemoryUserStore()
+	})
+}
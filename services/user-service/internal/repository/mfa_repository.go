@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// MFARepository handles database operations for multi-factor authentication
+type MFARepository struct {
+	db *sqlx.DB
+}
+
+// NewMFARepository creates a new MFARepository
+func NewMFARepository(db *sqlx.DB) *MFARepository {
+	return &MFARepository{
+		db: db,
+	}
+}
+
+// StartEnrollment stores a new TOTP secret for a user who does not have MFA enabled,
+// replacing the secret of an earlier unfinished enrollment. It returns sql.ErrNoRows
+// when MFA is already enabled.
+func (r *MFARepository) StartEnrollment(userID, secret string, audit *models.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to start MFA enrollment: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET mfa_secret = $2, mfa_last_step = NULL
+		WHERE id = $1 AND mfa_enabled_at IS NULL
+	`
+	if err := execOne(tx, query, userID, secret); err != nil {
+		return fmt.Errorf("failed to start MFA enrollment: %w", err)
+	}
+	if err := insertAuditEntries(tx, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to start MFA enrollment: %w", err)
+	}
+	return nil
+}
+
+// EnableMFA finishes an enrollment whose first code belonged to the given time step and
+// replaces the user's recovery codes. It returns sql.ErrNoRows when there is no pending
+// enrollment.
+func (r *MFARepository) EnableMFA(userID string, step int64, recoveryHashes []string, audit *models.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to enable MFA: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET mfa_enabled_at = NOW(), mfa_last_step = $2, updated_at = NOW()
+		WHERE id = $1 AND mfa_secret IS NOT NULL AND mfa_enabled_at IS NULL
+	`
+	if err := execOne(tx, query, userID, step); err != nil {
+		return fmt.Errorf("failed to enable MFA: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to replace recovery codes: %w", err)
+	}
+	for _, hash := range recoveryHashes {
+		if _, err := tx.Exec(`INSERT INTO mfa_recovery_codes (code_hash, user_id) VALUES ($1, $2)`, hash, userID); err != nil {
+			return fmt.Errorf("failed to create recovery code: %w", err)
+		}
+	}
+	if err := insertAuditEntries(tx, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to enable MFA: %w", err)
+	}
+	return nil
+}
+
+// CreateChallenge stores the challenge for the second step of a login
+func (r *MFARepository) CreateChallenge(challenge *models.MFAChallenge, audit *models.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to create MFA challenge: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO mfa_challenges (token_hash, user_id, expires_at, created_at)
+		VALUES (:token_hash, :user_id, :expires_at, :created_at)
+	`
+	if _, err := tx.NamedExec(query, challenge); err != nil {
+		return fmt.Errorf("failed to create MFA challenge: %w", err)
+	}
+	if err := insertAuditEntries(tx, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create MFA challenge: %w", err)
+	}
+	return nil
+}
+
+// GetChallengeUser gets the user of an unused, unexpired challenge that has attempts
+// left
+func (r *MFARepository) GetChallengeUser(tokenHash string, maxAttempts int) (*models.User, error) {
+	var user models.User
+	query := `
+		SELECT u.* FROM mfa_challenges c
+		JOIN users u ON u.id = c.user_id
+		WHERE c.token_hash = $1 AND c.used_at IS NULL AND c.expires_at > NOW() AND c.attempts < $2
+	`
+	if err := r.db.Get(&user, query, tokenHash, maxAttempts); err != nil {
+		return nil, fmt.Errorf("failed to get MFA challenge: %w", err)
+	}
+	return &user, nil
+}
+
+// CompleteChallenge answers a challenge with either a TOTP code that belongs to step or
+// a recovery code with the given hash; a zero step and empty hash reject the answer.
+// Accepted answers use up the challenge and the code and start the session.
+// Rejected answers use up one attempt. It returns sql.ErrNoRows when the challenge is no
+// longer valid.
+func (r *MFARepository) CompleteChallenge(tokenHash string, maxAttempts int, step int64, recoveryHash string, session *models.Session, refresh *models.RefreshToken, success, failure *models.AuditEntry) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to complete MFA challenge: %w", err)
+	}
+	defer tx.Rollback()
+
+	var userID string
+	query := `
+		SELECT user_id FROM mfa_challenges
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW() AND attempts < $2
+		FOR UPDATE
+	`
+	if err := tx.Get(&userID, query, tokenHash, maxAttempts); err != nil {
+		return false, fmt.Errorf("failed to complete MFA challenge: %w", err)
+	}
+
+	accepted, err := useCode(tx, userID, step, recoveryHash)
+	if err != nil {
+		return false, err
+	}
+
+	if accepted {
+		if _, err := tx.Exec(`UPDATE mfa_challenges SET used_at = NOW() WHERE token_hash = $1`, tokenHash); err != nil {
+			return false, fmt.Errorf("failed to complete MFA challenge: %w", err)
+		}
+		if err := insertSession(tx, session, refresh); err != nil {
+			return false, err
+		}
+		err = insertAuditEntries(tx, success)
+	} else {
+		if _, err := tx.Exec(`UPDATE mfa_challenges SET attempts = attempts + 1 WHERE token_hash = $1`, tokenHash); err != nil {
+			return false, fmt.Errorf("failed to record MFA attempt: %w", err)
+		}
+		err = insertAuditEntries(tx, failure)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to complete MFA challenge: %w", err)
+	}
+	return accepted, nil
+}
+
+// ResetMFA turns MFA off for a user and removes their secret, recovery codes and open
+// challenges. It returns sql.ErrNoRows when the user does not exist.
+func (r *MFARepository) ResetMFA(userID string, audit *models.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to reset MFA: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET mfa_secret = NULL, mfa_enabled_at = NULL, mfa_last_step = NULL, updated_at = NOW()
+		WHERE id = $1
+	`
+	if err := execOne(tx, query, userID); err != nil {
+		return fmt.Errorf("failed to reset MFA: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM mfa_challenges WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete MFA challenges: %w", err)
+	}
+	if err := insertAuditEntries(tx, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to reset MFA: %w", err)
+	}
+	return nil
+}
+
+// useCode marks a TOTP time step or a recovery code as used, reporting whether it was
+// still unused
+func useCode(tx *sqlx.Tx, userID string, step int64, recoveryHash string) (bool, error) {
+	var query string
+	var arg interface{}
+	switch {
+	case step > 0:
+		query = `
+			UPDATE users SET mfa_last_step = $2
+			WHERE id = $1 AND (mfa_last_step IS NULL OR mfa_last_step < $2)
+		`
+		arg = step
+	case recoveryHash != "":
+		query = `
+			UPDATE mfa_recovery_codes SET used_at = NOW()
+			WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+		`
+		arg = recoveryHash
+	default:
+		return false, nil
+	}
+
+	err := execOne(tx, query, userID, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to use MFA code: %w", err)
+	}
+	return true, nil
+}
+
+// execOne runs a statement that must affect exactly one row, returning sql.ErrNoRows
+// when it affects none
+func execOne(tx *sqlx.Tx, query string, args ...interface{}) error {
+	return execOneContext(context.Background(), tx, query, args...)
+}
+
+// execOneContext is execOne for statements that stop when ctx ends
+func execOneContext(ctx context.Context, tx *sqlx.Tx, query string, args ...interface{}) error {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// PasswordResetRepository handles database operations for password resets
+type PasswordResetRepository struct {
+	db *sqlx.DB
+}
+
+// NewPasswordResetRepository creates a new PasswordResetRepository
+func NewPasswordResetRepository(db *sqlx.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		db: db,
+	}
+}
+
+// CountRequests counts the reset requests made since the given time for an email
+// address and from an IP address
+func (r *PasswordResetRepository) CountRequests(email, ip string, since time.Time) (byEmail, byIP int, err error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE email = $1) AS by_email,
+			COUNT(*) FILTER (WHERE ip = $2) AS by_ip
+		FROM password_reset_requests
+		WHERE (email = $1 OR ip = $2) AND created_at >= $3
+	`
+	var counts struct {
+		ByEmail int `db:"by_email"`
+		ByIP    int `db:"by_ip"`
+	}
+	if err := r.db.Get(&counts, query, email, ip, since); err != nil {
+		return 0, 0, fmt.Errorf("failed to count password reset requests: %w", err)
+	}
+	return counts.ByEmail, counts.ByIP, nil
+}
+
+// RecordRequest records a reset request and, for existing accounts, replaces the user's
+// outstanding reset tokens with a new one and records the event that delivers it.
+// Requests older than retention are pruned.
+func (r *PasswordResetRepository) RecordRequest(email, ip string, token *models.PasswordResetToken, event *models.UserEvent, retention time.Duration) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to record password reset request: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM password_reset_requests WHERE created_at < $1`, time.Now().Add(-retention)); err != nil {
+		return fmt.Errorf("failed to prune password reset requests: %w", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO password_reset_requests (email, ip) VALUES ($1, $2)`, email, ip); err != nil {
+		return fmt.Errorf("failed to record password reset request: %w", err)
+	}
+
+	if token != nil {
+		if _, err := tx.Exec(`DELETE FROM password_reset_tokens WHERE user_id = $1 AND used_at IS NULL`, token.UserID); err != nil {
+			return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+		}
+		query := `
+			INSERT INTO password_reset_tokens (token_hash, user_id, expires_at, created_at)
+			VALUES (:token_hash, :user_id, :expires_at, :created_at)
+		`
+		if _, err := tx.NamedExec(query, token); err != nil {
+			return fmt.Errorf("failed to create password reset token: %w", err)
+		}
+		if err := insertEvents(tx, []*models.UserEvent{event}); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record password reset request: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword consumes an unused, unexpired reset token, sets the user's password hash
+// and ends all of the user's sessions. It returns sql.ErrNoRows when the token
+// is invalid.
+func (r *PasswordResetRepository) ResetPassword(tokenHash, passwordHash string) (string, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return "", fmt.Errorf("failed to reset password: %w", err)
+	}
+	defer tx.Rollback()
+
+	var userID string
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`
+	if err := tx.Get(&userID, query, tokenHash); err != nil {
+		return "", fmt.Errorf("failed to reset password: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE users SET password_hash = $2, updated_at = NOW() WHERE id = $1`, userID, passwordHash); err != nil {
+		return "", fmt.Errorf("failed to update password: %w", err)
+	}
+	if _, err := revokeSessions(tx, userID, ""); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to reset password: %w", err)
+	}
+	return userID, nil
+}
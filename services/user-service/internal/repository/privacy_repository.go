@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// PrivacyRepository handles database operations for data export and erasure requests
+type PrivacyRepository struct {
+	db *sqlx.DB
+}
+
+// NewPrivacyRepository creates a new PrivacyRepository
+func NewPrivacyRepository(db *sqlx.DB) *PrivacyRepository {
+	return &PrivacyRepository{
+		db: db,
+	}
+}
+
+// GetUserExport gets everything held about a user from a single snapshot and records the
+// audit entry for the export. It returns sql.ErrNoRows when the user does not exist.
+func (r *PrivacyRepository) GetUserExport(id string, audit *models.AuditEntry) (*models.UserExport, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to export user: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Every query sees the same snapshot
+	if _, err := tx.Exec(`SET TRANSACTION ISOLATION LEVEL REPEATABLE READ`); err != nil {
+		return nil, fmt.Errorf("failed to export user: %w", err)
+	}
+
+	export := &models.UserExport{
+		Profile:  &models.User{},
+		History:  []models.UserVersion{},
+		AuditLog: []models.AuditEntry{},
+		Sessions: []models.Session{},
+		APIKeys:  []models.APIKey{},
+	}
+	if err := tx.Get(export.Profile, `SELECT * FROM users WHERE id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to export user: %w", err)
+	}
+
+	queries := []struct {
+		dest  interface{}
+		query string
+	}{
+		{&export.History, `SELECT * FROM users_history WHERE user_id = $1 ORDER BY id`},
+		{&export.AuditLog, `SELECT * FROM audit_log WHERE target_id = $1 OR actor_id = $1 ORDER BY id`},
+		{&export.Sessions, `SELECT * FROM sessions WHERE user_id = $1 ORDER BY created_at`},
+		{&export.APIKeys, `SELECT * FROM api_keys WHERE user_id = $1 ORDER BY created_at`},
+	}
+	for _, q := range queries {
+		if err := tx.Select(q.dest, q.query, id); err != nil {
+			return nil, fmt.Errorf("failed to export user: %w", err)
+		}
+	}
+
+	if err := insertAuditEntries(tx, audit); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to export user: %w", err)
+	}
+	return export, nil
+}
+
+// EraseUser replaces a user's email address and name with the given placeholders and
+// removes the rest of their personal data: credentials, sessions, API keys, pending
+// tokens, login throttling state, earlier profile versions, outbox event data, and the
+// IPs and changed values of their audit entries. The audit entry and the erasure event
+// are recorded in the same transaction. Users that were already erased are left alone,
+// and the time of the first erasure is returned. It returns sql.ErrNoRows when the user
+// does not exist.
+func (r *PrivacyRepository) EraseUser(id, email, name string, now time.Time, audit *models.AuditEntry, event *models.UserEvent) (*models.Erasure, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to erase user: %w", err)
+	}
+	defer tx.Rollback()
+
+	var user models.User
+	if err := tx.Get(&user, `SELECT * FROM users WHERE id = $1 FOR UPDATE`, id); err != nil {
+		return nil, fmt.Errorf("failed to erase user: %w", err)
+	}
+	if user.ErasedAt != nil {
+		return &models.Erasure{UserID: id, ErasedAt: *user.ErasedAt}, nil
+	}
+
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`
+			UPDATE users
+			SET email = $2, name = $3, password_hash = NULL, email_verified_at = NULL,
+				mfa_secret = NULL, mfa_enabled_at = NULL, mfa_last_step = NULL,
+				erased_at = $4, updated_at = $4
+			WHERE id = $1
+		`, []interface{}{id, email, name, now}},
+		// Runs after the update above, whose version the history trigger has just added
+		{`UPDATE users_history SET email = $2, name = $3 WHERE user_id = $1`, []interface{}{id, email, name}},
+		// Refresh tokens of the sessions go with them
+		{`DELETE FROM sessions WHERE user_id = $1`, []interface{}{id}},
+		{`DELETE FROM refresh_tokens WHERE user_id = $1`, []interface{}{id}},
+		{`DELETE FROM api_keys WHERE user_id = $1`, []interface{}{id}},
+		{`DELETE FROM email_verification_tokens WHERE user_id = $1`, []interface{}{id}},
+		{`DELETE FROM password_reset_tokens WHERE user_id = $1`, []interface{}{id}},
+		{`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, []interface{}{id}},
+		{`DELETE FROM mfa_challenges WHERE user_id = $1`, []interface{}{id}},
+		// Throttling state and reset requests are kept by lowercased address
+		{`DELETE FROM login_attempts WHERE scope = 'account' AND key = lower(btrim($1))`, []interface{}{user.Email}},
+		{`DELETE FROM password_reset_requests WHERE email = lower(btrim($1))`, []interface{}{user.Email}},
+		// Events that have not gone out yet would mail the old address
+		{`DELETE FROM user_events WHERE user_id = $1 AND delivered_at IS NULL`, []interface{}{id}},
+		{`UPDATE user_events SET data = '{}' WHERE user_id = $1`, []interface{}{id}},
+		{`
+			UPDATE audit_log SET ip = NULL, changes = NULL, redacted_at = $2
+			WHERE target_id = $1 AND redacted_at IS NULL
+		`, []interface{}{id, now}},
+		// Changes the user made to other records describe those records and are kept
+		{`
+			UPDATE audit_log SET ip = NULL, redacted_at = $2
+			WHERE actor_id = $1 AND target_id IS DISTINCT FROM $1 AND redacted_at IS NULL
+		`, []interface{}{id, now}},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt.query, stmt.args...); err != nil {
+			return nil, fmt.Errorf("failed to erase user: %w", err)
+		}
+	}
+
+	if err := insertAuditEntries(tx, audit); err != nil {
+		return nil, err
+	}
+	if err := insertEvents(tx, []*models.UserEvent{event}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to erase user: %w", err)
+	}
+	return &models.Erasure{UserID: id, ErasedAt: now}, nil
+}
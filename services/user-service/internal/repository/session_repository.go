@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// activeSession matches sessions that are not revoked and still have a usable refresh token
+const activeSession = `
+	s.revoked_at IS NULL AND EXISTS (
+		SELECT 1 FROM refresh_tokens t
+		WHERE t.session_id = s.id AND t.revoked_at IS NULL AND t.expires_at > NOW()
+	)
+`
+
+// SessionRepository handles database operations for sessions
+type SessionRepository struct {
+	db *sqlx.DB
+}
+
+// NewSessionRepository creates a new SessionRepository
+func NewSessionRepository(db *sqlx.DB) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+// CreateSession stores a new session with its first refresh token
+func (r *SessionRepository) CreateSession(session *models.Session, token *models.RefreshToken) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertSession(tx, session, token); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// GetSessions gets a user's active sessions, most recently seen first
+func (r *SessionRepository) GetSessions(userID string) ([]models.Session, error) {
+	sessions := []models.Session{}
+	query := `SELECT s.* FROM sessions s WHERE s.user_id = $1 AND` + activeSession + `ORDER BY s.last_seen_at DESC`
+	if err := r.db.Select(&sessions, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// IsSessionActive reports whether a session is still active
+func (r *SessionRepository) IsSessionActive(id string) (bool, error) {
+	var active bool
+	query := `SELECT EXISTS (SELECT 1 FROM sessions s WHERE s.id = $1 AND` + activeSession + `)`
+	if err := r.db.Get(&active, query, id); err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+	return active, nil
+}
+
+// RevokeSession ends one of a user's sessions and revokes its refresh tokens. It returns
+// sql.ErrNoRows when the user has no such session.
+func (r *SessionRepository) RevokeSession(userID, id string, audit *models.AuditEntry) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	if err := execOne(tx, query, id, userID); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	tokens := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE session_id = $1 AND revoked_at IS NULL`
+	if _, err := tx.Exec(tokens, id); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	if err := insertAuditEntries(tx, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeSessions ends all of a user's sessions except exceptID, if set, and revokes their
+// refresh tokens. It returns the number of sessions ended.
+func (r *SessionRepository) RevokeSessions(userID, exceptID string, audit *models.AuditEntry) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	defer tx.Rollback()
+
+	n, err := revokeSessions(tx, userID, exceptID)
+	if err != nil {
+		return 0, err
+	}
+	if err := insertAuditEntries(tx, audit); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return n, nil
+}
+
+// insertSession stores a new session with its first refresh token as part of tx
+func insertSession(tx *sqlx.Tx, session *models.Session, token *models.RefreshToken) error {
+	query := `
+		INSERT INTO sessions (id, user_id, device, ip, user_agent, created_at, last_seen_at)
+		VALUES (:id, :user_id, :device, :ip, :user_agent, :created_at, :last_seen_at)
+	`
+	if _, err := tx.NamedExec(query, session); err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	insert := `
+		INSERT INTO refresh_tokens (id, user_id, session_id, token_hash, expires_at, created_at)
+		VALUES (:id, :user_id, :session_id, :token_hash, :expires_at, :created_at)
+	`
+	if _, err := tx.NamedExec(insert, token); err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return nil
+}
+
+// revokeSessions ends a user's sessions other than exceptID as part of tx and revokes
+// all of their refresh tokens, including any not tied to a session
+func revokeSessions(tx *sqlx.Tx, userID, exceptID string) (int, error) {
+	var ids []string
+	query := `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL AND ($2 = '' OR id::text <> $2)
+		RETURNING id
+	`
+	if err := tx.Select(&ids, query, userID, exceptID); err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	tokens := `
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL AND ($2 = '' OR session_id IS NULL OR session_id::text <> $2)
+	`
+	if _, err := tx.Exec(tokens, userID, exceptID); err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return len(ids), nil
+}
@@ -0,0 +1,422 @@
+// Package storetest holds a conformance suite for repository.UserStore implementations,
+// so that the in-memory store can stand in for Postgres in tests
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+)
+
+// TestUserStore runs the conformance suite against the stores newStore returns. Stores
+// may be shared between tests and hold other users; every test works on users of its own.
+func TestUserStore(t *testing.T, newStore func(t *testing.T) repository.UserStore) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store repository.UserStore)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"NotFound", testNotFound},
+		{"DuplicateEmail", testDuplicateEmail},
+		{"Update", testUpdate},
+		{"Delete", testDelete},
+		{"InTx", testInTx},
+		{"ExportUsers", testExportUsers},
+		{"ConcurrentCreates", testConcurrentCreates},
+		{"ContextEnded", testContextEnded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+func testCreateAndGet(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	user := createUser(t, store)
+
+	got, err := store.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if got.Email != user.Email || got.Name != user.Name || got.Role != models.RoleUser {
+		t.Errorf("GetUserByID = %+v, want %+v with role %q", got, user, models.RoleUser)
+	}
+	if !got.CreatedAt.Equal(user.CreatedAt) {
+		t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, user.CreatedAt)
+	}
+
+	users, err := store.GetUsersByIDs(ctx, []string{user.ID, uuid.New().String()})
+	if err != nil {
+		t.Fatalf("GetUsersByIDs: %v", err)
+	}
+	if len(users) != 1 || users[0].ID != user.ID {
+		t.Errorf("GetUsersByIDs = %+v, want only %s", users, user.ID)
+	}
+
+	all, err := store.GetUsers(ctx)
+	if err != nil {
+		t.Fatalf("GetUsers: %v", err)
+	}
+	if !containsUser(all, user.ID) {
+		t.Errorf("GetUsers does not include %s", user.ID)
+	}
+}
+
+func testNotFound(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	id := uuid.New().String()
+
+	if _, err := store.GetUserByID(ctx, id); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUserByID: got %v, want sql.ErrNoRows", err)
+	}
+	if _, err := store.GetUserAsOf(ctx, id, time.Now()); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUserAsOf: got %v, want sql.ErrNoRows", err)
+	}
+
+	missing := newUser()
+	missing.ID = id
+	if err := store.UpdateUser(ctx, missing, nil, auditEntry(models.AuditUserUpdated, id)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("UpdateUser: got %v, want sql.ErrNoRows", err)
+	}
+	if err := store.DeleteUser(ctx, id, auditEntry(models.AuditUserDeleted, id)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("DeleteUser: got %v, want sql.ErrNoRows", err)
+	}
+
+	versions, err := store.GetUserHistory(ctx, id, 10, 0)
+	if err != nil {
+		t.Fatalf("GetUserHistory: %v", err)
+	}
+	if len(versions) != 0 {
+		t.Errorf("GetUserHistory = %+v, want no versions", versions)
+	}
+}
+
+func testDuplicateEmail(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	first := createUser(t, store)
+	second := createUser(t, store)
+
+	duplicate := newUser()
+	duplicate.Email = first.Email
+	err := store.CreateUser(ctx, duplicate, nil, auditEntry(models.AuditUserCreated, duplicate.ID))
+	if !errors.Is(err, repository.ErrDuplicateEmail) {
+		t.Errorf("CreateUser: got %v, want ErrDuplicateEmail", err)
+	}
+	if _, err := store.GetUserByID(ctx, duplicate.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUserByID after failed create: got %v, want sql.ErrNoRows", err)
+	}
+
+	second.Email = first.Email
+	err = store.UpdateUser(ctx, second, nil, auditEntry(models.AuditUserUpdated, second.ID))
+	if !errors.Is(err, repository.ErrDuplicateEmail) {
+		t.Errorf("UpdateUser: got %v, want ErrDuplicateEmail", err)
+	}
+}
+
+func testUpdate(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	user := createUser(t, store)
+
+	user.Name = "Renamed"
+	user.UpdatedAt = user.UpdatedAt.Add(time.Second)
+	if err := store.UpdateUser(ctx, user, nil, auditEntry(models.AuditUserUpdated, user.ID)); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+
+	got, err := store.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if got.Name != "Renamed" || !got.UpdatedAt.Equal(user.UpdatedAt) {
+		t.Errorf("GetUserByID = %+v, want name Renamed updated at %v", got, user.UpdatedAt)
+	}
+
+	versions, err := store.GetUserHistory(ctx, user.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("GetUserHistory: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("GetUserHistory returned %d versions, want 2", len(versions))
+	}
+	if v := versions[0]; v.Operation != models.HistoryUpdate || len(v.ChangedFields) != 1 || v.ChangedFields[0] != "name" || v.ValidTo != nil {
+		t.Errorf("latest version = %+v, want an open update of name", v)
+	}
+	if v := versions[1]; v.Operation != models.HistoryInsert || v.Name != newUserName || v.ValidTo == nil {
+		t.Errorf("first version = %+v, want a closed insert", v)
+	}
+
+	page, err := store.GetUserHistory(ctx, user.ID, 1, 1)
+	if err != nil {
+		t.Fatalf("GetUserHistory: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != versions[1].ID {
+		t.Errorf("GetUserHistory(limit 1, offset 1) = %+v, want the first version", page)
+	}
+
+	// Updates that leave the profile alone add no version
+	if err := store.UpdateUser(ctx, user, nil, auditEntry(models.AuditUserUpdated, user.ID)); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	versions, err = store.GetUserHistory(ctx, user.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("GetUserHistory: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Errorf("GetUserHistory returned %d versions after an unchanged update, want 2", len(versions))
+	}
+}
+
+func testDelete(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	user := createUser(t, store)
+	if err := store.DeleteUser(ctx, user.ID, auditEntry(models.AuditUserDeleted, user.ID)); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+
+	if _, err := store.GetUserByID(ctx, user.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUserByID: got %v, want sql.ErrNoRows", err)
+	}
+
+	versions, err := store.GetUserHistory(ctx, user.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("GetUserHistory: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Operation != models.HistoryDelete {
+		t.Fatalf("GetUserHistory = %+v, want a delete after the insert", versions)
+	}
+
+	// Versions are dated by the store's clock, so they are the reference for as_of
+	got, err := store.GetUserAsOf(ctx, user.ID, versions[1].ValidFrom)
+	if err != nil {
+		t.Fatalf("GetUserAsOf before the delete: %v", err)
+	}
+	if got.Email != user.Email {
+		t.Errorf("GetUserAsOf = %+v, want %s", got, user.Email)
+	}
+	if _, err := store.GetUserAsOf(ctx, user.ID, versions[0].ValidFrom); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUserAsOf after the delete: got %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testInTx(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	rolledBack := newUser()
+	failure := errors.New("failure")
+	err := store.InTx(ctx, func(tx repository.UserWriter) error {
+		if err := tx.CreateUser(ctx, rolledBack, nil, auditEntry(models.AuditUserCreated, rolledBack.ID)); err != nil {
+			return err
+		}
+		// The transaction sees its own changes
+		if _, err := tx.GetUserByID(ctx, rolledBack.ID); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("InTx: got %v, want the error of fn", err)
+	}
+	if _, err := store.GetUserByID(ctx, rolledBack.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUserByID after rollback: got %v, want sql.ErrNoRows", err)
+	}
+
+	existing := createUser(t, store)
+	committed := newUser()
+	err = store.InTx(ctx, func(tx repository.UserWriter) error {
+		if err := tx.CreateUser(ctx, committed, nil, auditEntry(models.AuditUserCreated, committed.ID)); err != nil {
+			return err
+		}
+		return tx.DeleteUser(ctx, existing.ID, auditEntry(models.AuditUserDeleted, existing.ID))
+	})
+	if err != nil {
+		t.Fatalf("InTx: %v", err)
+	}
+	if _, err := store.GetUserByID(ctx, committed.ID); err != nil {
+		t.Errorf("GetUserByID after commit: %v", err)
+	}
+	if _, err := store.GetUserByID(ctx, existing.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUserByID of deleted user after commit: got %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testExportUsers(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	var created []string
+	for i := 0; i < 3; i++ {
+		created = append(created, createUser(t, store).ID)
+	}
+
+	var exported []string
+	err := store.ExportUsers(ctx, func(user *models.User) error {
+		for _, id := range created {
+			if user.ID == id {
+				exported = append(exported, id)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportUsers: %v", err)
+	}
+	if len(exported) != len(created) {
+		t.Fatalf("ExportUsers passed %v, want %v", exported, created)
+	}
+	for i := range created {
+		if exported[i] != created[i] {
+			t.Errorf("ExportUsers passed %v, want oldest first %v", exported, created)
+			break
+		}
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = store.ExportUsers(ctx, func(user *models.User) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("ExportUsers stopped after %d calls with %v, want 1 call and the error of fn", calls, err)
+	}
+}
+
+func testConcurrentCreates(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	const n = 8
+	users := make([]*models.User, n)
+	for i := range users {
+		users[i] = newUser()
+		users[i].Email = users[0].Email
+	}
+
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for _, user := range users {
+		wg.Add(1)
+		go func(user *models.User) {
+			defer wg.Done()
+			errs <- store.CreateUser(ctx, user, nil, auditEntry(models.AuditUserCreated, user.ID))
+		}(user)
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, repository.ErrDuplicateEmail):
+			t.Errorf("CreateUser: got %v, want ErrDuplicateEmail", err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d of %d creates with the same address succeeded, want 1", created, n)
+	}
+}
+
+func testContextEnded(t *testing.T, store repository.UserStore) {
+	user := createUser(t, store)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := store.GetUserByID(canceled, user.ID); !errors.Is(err, repository.ErrCanceled) {
+		t.Errorf("GetUserByID with a canceled context: got %v, want ErrCanceled", err)
+	}
+	created := newUser()
+	err := store.CreateUser(canceled, created, nil, auditEntry(models.AuditUserCreated, created.ID))
+	if !errors.Is(err, repository.ErrCanceled) {
+		t.Errorf("CreateUser with a canceled context: got %v, want ErrCanceled", err)
+	}
+	if _, err := store.GetUserByID(context.Background(), created.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUserByID after a canceled create: got %v, want sql.ErrNoRows", err)
+	}
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if _, err := store.GetUsers(expired); !errors.Is(err, repository.ErrTimeout) {
+		t.Errorf("GetUsers past the deadline: got %v, want ErrTimeout", err)
+	}
+	err = store.ExportUsers(expired, func(user *models.User) error { return nil })
+	if !errors.Is(err, repository.ErrTimeout) {
+		t.Errorf("ExportUsers past the deadline: got %v, want ErrTimeout", err)
+	}
+}
+
+// newUserName is the name of the users newUser makes
+const newUserName = "Test User"
+
+// lastCreatedAt keeps the users of a test in creation order. Users are made before any
+// goroutines that use them are started.
+var lastCreatedAt time.Time
+
+// newUser makes a user with a unique address. Times have the microsecond precision of
+// Postgres and increase from user to user.
+func newUser() *models.User {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	if !now.After(lastCreatedAt) {
+		now = lastCreatedAt.Add(time.Microsecond)
+	}
+	lastCreatedAt = now
+
+	id := uuid.New().String()
+	return &models.User{
+		ID:        id,
+		Email:     id + "@example.com",
+		Name:      newUserName,
+		Role:      models.RoleUser,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// createUser stores a new user with a verification token and a created event
+func createUser(t *testing.T, store repository.UserStore) *models.User {
+	t.Helper()
+	ctx := context.Background()
+
+	user := newUser()
+	verification := &models.EmailVerificationToken{
+		TokenHash: uuid.New().String(),
+		UserID:    user.ID,
+		Email:     user.Email,
+		ExpiresAt: user.CreatedAt.Add(time.Hour),
+		CreatedAt: user.CreatedAt,
+	}
+	event := &models.UserEvent{
+		ID:        uuid.New().String(),
+		Type:      models.EventUserCreated,
+		UserID:    user.ID,
+		Data:      models.EventData{Email: user.Email, Name: user.Name},
+		CreatedAt: user.CreatedAt,
+	}
+	if err := store.CreateUser(ctx, user, verification, auditEntry(models.AuditUserCreated, user.ID), event); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return user
+}
+
+func auditEntry(action, userID string) *models.AuditEntry {
+	return &models.AuditEntry{
+		Action:    action,
+		TargetID:  &userID,
+		Metadata:  models.AuditMetadata{},
+		CreatedAt: time.Now(),
+	}
+}
+
+func containsUser(users []models.User, id string) bool {
+	for _, user := range users {
+		if user.ID == id {
+			return true
+		}
+	}
+	return false
+}
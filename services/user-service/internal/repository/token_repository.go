@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// TokenRepository handles database operations for refresh tokens
+type TokenRepository struct {
+	db *sqlx.DB
+}
+
+// NewTokenRepository creates a new TokenRepository
+func NewTokenRepository(db *sqlx.DB) *TokenRepository {
+	return &TokenRepository{
+		db: db,
+	}
+}
+
+// GetRefreshTokenUserID gets the user an active refresh token belongs to. It returns
+// sql.ErrNoRows under the same conditions as RotateRefreshToken.
+func (r *TokenRepository) GetRefreshTokenUserID(hash string) (string, error) {
+	var userID string
+	query := `
+		SELECT user_id FROM refresh_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+			AND session_id IN (SELECT id FROM sessions WHERE revoked_at IS NULL)
+	`
+	if err := r.db.Get(&userID, query, hash); err != nil {
+		return "", fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	return userID, nil
+}
+
+// RotateRefreshToken revokes an active refresh token and stores its replacement in the
+// same session, recording where the session was last seen from. It returns
+// sql.ErrNoRows when the old token is unknown, expired or revoked or its session has
+// ended, so each refresh token can be used only once.
+func (r *TokenRepository) RotateRefreshToken(oldHash string, next *models.RefreshToken, client models.Client) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+			AND session_id IN (SELECT id FROM sessions WHERE revoked_at IS NULL)
+		RETURNING user_id, session_id
+	`
+	if err := tx.QueryRowx(query, oldHash).Scan(&next.UserID, &next.SessionID); err != nil {
+		return fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	seen := `UPDATE sessions SET last_seen_at = NOW(), ip = $2, user_agent = $3 WHERE id = $1`
+	if _, err := tx.Exec(seen, next.SessionID, client.IP, client.UserAgent); err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+
+	insert := `
+		INSERT INTO refresh_tokens (id, user_id, session_id, token_hash, expires_at, created_at)
+		VALUES (:id, :user_id, :session_id, :token_hash, :expires_at, :created_at)
+	`
+	if _, err := tx.NamedExec(insert, next); err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	return nil
+}
+
+// RevokeRefreshToken revokes a refresh token and ends its session
+func (r *TokenRepository) RevokeRefreshToken(hash string, now time.Time) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	defer tx.Rollback()
+
+	session := `
+		UPDATE sessions SET revoked_at = $2
+		WHERE id = (SELECT session_id FROM refresh_tokens WHERE token_hash = $1) AND revoked_at IS NULL
+	`
+	if _, err := tx.Exec(session, hash, now); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	query := `UPDATE refresh_tokens SET revoked_at = $2 WHERE token_hash = $1 AND revoked_at IS NULL`
+	if _, err := tx.Exec(query, hash, now); err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// exportBatchSize is how many users are fetched from the export cursor at a time
+const exportBatchSize = 1000
+
+// ExportUsers passes every user to fn, oldest first, stopping at the first error fn
+// returns or when ctx ends. Users are read through a cursor in a read-only transaction,
+// so memory use does not grow with the table and every user comes from the same snapshot.
+func (r *UserRepository) ExportUsers(ctx context.Context, fn func(user *models.User) error) error {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to export users: %w", contextError(ctx, err))
+	}
+	defer tx.Rollback()
+
+	query := `DECLARE user_export NO SCROLL CURSOR FOR SELECT * FROM users ORDER BY created_at, id`
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to export users: %w", contextError(ctx, err))
+	}
+
+	users := make([]models.User, 0, exportBatchSize)
+	for {
+		users = users[:0]
+		if err := tx.SelectContext(ctx, &users, fmt.Sprintf(`FETCH %d FROM user_export`, exportBatchSize)); err != nil {
+			return fmt.Errorf("failed to export users: %w", contextError(ctx, err))
+		}
+		for i := range users {
+			if err := fn(&users[i]); err != nil {
+				return err
+			}
+		}
+		if len(users) < exportBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// The users_history table is maintained by a trigger on users, so it is only read here
+
+// GetUserAsOf gets a user as they were at asOf. It returns sql.ErrNoRows when the user
+// did not exist then.
+func (r *UserRepository) GetUserAsOf(ctx context.Context, id string, asOf time.Time) (*models.User, error) {
+	var user models.User
+	query := `
+		SELECT user_id AS id, email, name, role, email_verified_at, mfa_enabled_at, created_at, updated_at
+		FROM users_history
+		WHERE user_id = $1 AND operation <> $2
+			AND valid_from <= $3 AND (valid_to IS NULL OR valid_to > $3)
+		ORDER BY id DESC
+		LIMIT 1
+	`
+	err := r.db.GetContext(ctx, &user, query, id, models.HistoryDelete, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", contextError(ctx, err))
+	}
+	return &user, nil
+}
+
+// GetUserHistory gets a page of a user's versions, newest first
+func (r *UserRepository) GetUserHistory(ctx context.Context, id string, limit, offset int) ([]models.UserVersion, error) {
+	versions := []models.UserVersion{}
+	query := `
+		SELECT * FROM users_history
+		WHERE user_id = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3
+	`
+	err := r.db.SelectContext(ctx, &versions, query, id, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user history: %w", contextError(ctx, err))
+	}
+	return versions, nil
+}
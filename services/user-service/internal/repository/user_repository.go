@@ -1,9 +1,13 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/yourusername/go-microservices/user-service/internal/models"
 )
 
@@ -20,60 +24,195 @@ func NewUserRepository(db *sqlx.DB) *UserRepository {
 }
 
 // GetUsers gets all users
-func (r *UserRepository) GetUsers() ([]models.User, error) {
+func (r *UserRepository) GetUsers(ctx context.Context) ([]models.User, error) {
 	var users []models.User
 	query := `SELECT * FROM users`
-	err := r.db.Select(&users, query)
+	err := r.db.SelectContext(ctx, &users, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get users: %w", err)
+		return nil, fmt.Errorf("failed to get users: %w", contextError(ctx, err))
 	}
 	return users, nil
 }
 
 // GetUserByID gets a user by ID
-func (r *UserRepository) GetUserByID(id string) (*models.User, error) {
+func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*models.User, error) {
 	var user models.User
 	query := `SELECT * FROM users WHERE id = $1`
-	err := r.db.Get(&user, query, id)
+	err := r.db.GetContext(ctx, &user, query, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, fmt.Errorf("failed to get user: %w", contextError(ctx, err))
 	}
 	return &user, nil
 }
 
-// CreateUser creates a new user
-func (r *UserRepository) CreateUser(user *models.User) error {
-	query := `
-		INSERT INTO users (id, email, name, created_at, updated_at)
-		VALUES (:id, :email, :name, :created_at, :updated_at)
-	`
-	_, err := r.db.NamedExec(query, user)
+// GetUserByEmail gets a user by email address
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	query := `SELECT * FROM users WHERE email = $1`
+	err := r.db.GetContext(ctx, &user, query, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", contextError(ctx, err))
+	}
+	return &user, nil
+}
+
+// CreateUser creates a new user and, in the same transaction, stores the verification
+// token for their address, if any, and records the audit entry and the given events
+func (r *UserRepository) CreateUser(ctx context.Context, user *models.User, verification *models.EmailVerificationToken, audit *models.AuditEntry, events ...*models.UserEvent) error {
+	return r.inTx(ctx, "create user", func(tx *sqlx.Tx) error {
+		return createUser(ctx, tx, user, verification, audit, events)
+	})
+}
+
+// UpdateUser updates a user and, in the same transaction, stores the verification token
+// for their address, if any, and records the audit entry and the given events
+func (r *UserRepository) UpdateUser(ctx context.Context, user *models.User, verification *models.EmailVerificationToken, audit *models.AuditEntry, events ...*models.UserEvent) error {
+	return r.inTx(ctx, "update user", func(tx *sqlx.Tx) error {
+		return updateUser(ctx, tx, user, verification, audit, events)
+	})
+}
+
+// DeleteUser deletes a user and records the audit entry in the same transaction. It
+// returns sql.ErrNoRows when the user does not exist.
+func (r *UserRepository) DeleteUser(ctx context.Context, id string, audit *models.AuditEntry) error {
+	return r.inTx(ctx, "delete user", func(tx *sqlx.Tx) error {
+		return deleteUser(ctx, tx, id, audit)
+	})
+}
+
+// GetUsersByIDs gets the users with the given IDs, in no particular order
+func (r *UserRepository) GetUsersByIDs(ctx context.Context, ids []string) ([]models.User, error) {
+	users := []models.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+	query, args, err := sqlx.In(`SELECT * FROM users WHERE id IN (?)`, ids)
 	if err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	if err := r.db.SelectContext(ctx, &users, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", contextError(ctx, err))
+	}
+	return users, nil
+}
+
+// InTx runs fn with a UserTx whose changes are committed together when fn returns nil,
+// and rolled back otherwise
+func (r *UserRepository) InTx(ctx context.Context, fn func(tx UserWriter) error) error {
+	return r.inTx(ctx, "apply changes", func(tx *sqlx.Tx) error {
+		return fn(&UserTx{tx: tx})
+	})
+}
+
+// inTx runs fn in a transaction, committing it when fn returns nil. action names what
+// fn does in errors. The transaction is rolled back when ctx ends, which fails the
+// statements fn has left to run.
+func (r *UserRepository) inTx(ctx context.Context, action string, fn func(tx *sqlx.Tx) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, contextError(ctx, err))
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return contextError(ctx, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to %s: %w", action, contextError(ctx, err))
 	}
 	return nil
 }
 
-// UpdateUser updates a user
-func (r *UserRepository) UpdateUser(user *models.User) error {
+// UserTx makes the changes of UserRepository within a single transaction. Users it reads
+// are locked until the transaction ends.
+type UserTx struct {
+	tx *sqlx.Tx
+}
+
+// GetUserByID gets and locks a user by ID
+func (t *UserTx) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	var user models.User
+	if err := t.tx.GetContext(ctx, &user, `SELECT * FROM users WHERE id = $1 FOR UPDATE`, id); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", contextError(ctx, err))
+	}
+	return &user, nil
+}
+
+// CreateUser creates a new user as UserRepository.CreateUser does
+func (t *UserTx) CreateUser(ctx context.Context, user *models.User, verification *models.EmailVerificationToken, audit *models.AuditEntry, events ...*models.UserEvent) error {
+	return contextError(ctx, createUser(ctx, t.tx, user, verification, audit, events))
+}
+
+// UpdateUser updates a user as UserRepository.UpdateUser does
+func (t *UserTx) UpdateUser(ctx context.Context, user *models.User, verification *models.EmailVerificationToken, audit *models.AuditEntry, events ...*models.UserEvent) error {
+	return contextError(ctx, updateUser(ctx, t.tx, user, verification, audit, events))
+}
+
+// DeleteUser deletes a user as UserRepository.DeleteUser does
+func (t *UserTx) DeleteUser(ctx context.Context, id string, audit *models.AuditEntry) error {
+	return contextError(ctx, deleteUser(ctx, t.tx, id, audit))
+}
+
+func createUser(ctx context.Context, tx *sqlx.Tx, user *models.User, verification *models.EmailVerificationToken, audit *models.AuditEntry, events []*models.UserEvent) error {
+	query := `
+		INSERT INTO users (id, email, name, password_hash, email_verified_at, created_at, updated_at)
+		VALUES (:id, :email, :name, :password_hash, :email_verified_at, :created_at, :updated_at)
+	`
+	if _, err := tx.NamedExecContext(ctx, query, user); err != nil {
+		return fmt.Errorf("failed to create user: %w", emailError(err))
+	}
+	if verification != nil {
+		if err := insertVerificationToken(tx, verification); err != nil {
+			return err
+		}
+	}
+	if err := insertAuditEntries(tx, audit); err != nil {
+		return err
+	}
+	return insertEvents(tx, events)
+}
+
+func updateUser(ctx context.Context, tx *sqlx.Tx, user *models.User, verification *models.EmailVerificationToken, audit *models.AuditEntry, events []*models.UserEvent) error {
 	query := `
 		UPDATE users
-		SET email = :email, name = :name, updated_at = :updated_at
+		SET email = :email, name = :name, email_verified_at = :email_verified_at, updated_at = :updated_at
 		WHERE id = :id
 	`
-	_, err := r.db.NamedExec(query, user)
+	result, err := tx.NamedExecContext(ctx, query, user)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", emailError(err))
+	}
+	n, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
-	return nil
+	if n == 0 {
+		return fmt.Errorf("failed to update user: %w", sql.ErrNoRows)
+	}
+	if verification != nil {
+		if err := insertVerificationToken(tx, verification); err != nil {
+			return err
+		}
+	}
+	if err := insertAuditEntries(tx, audit); err != nil {
+		return err
+	}
+	return insertEvents(tx, events)
 }
 
-// DeleteUser deletes a user
-func (r *UserRepository) DeleteUser(id string) error {
-	query := `DELETE FROM users WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	if err != nil {
+// emailError turns violations of the unique constraint on users.email into
+// ErrDuplicateEmail
+func emailError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "users_email_key" {
+		return ErrDuplicateEmail
+	}
+	return err
+}
+
+func deleteUser(ctx context.Context, tx *sqlx.Tx, id string, audit *models.AuditEntry) error {
+	if err := execOneContext(ctx, tx, `DELETE FROM users WHERE id = $1`, id); err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
-	return nil
+	return insertAuditEntries(tx, audit)
 }
\ No newline at end of file
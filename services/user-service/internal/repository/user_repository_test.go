@@ -0,0 +1,34 @@
+package repository_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yourusername/go-microservices/user-service/internal/database"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+	"github.com/yourusername/go-microservices/user-service/internal/repository/storetest"
+)
+
+// TestUserRepository runs the conformance suite against the Postgres database at
+// TEST_DATABASE_URL, after applying the embedded migrations to it. The tests add users to
+// it and leave them there.
+func TestUserRepository(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	if err := database.MigrateUp(dsn, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	db, err := database.Connect(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := repository.NewUserRepository(db)
+	storetest.TestUserStore(t, func(t *testing.T) repository.UserStore {
+		return repo
+	})
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// ErrDuplicateEmail is returned when creating or updating a user with an address that
+// another user already has
+var ErrDuplicateEmail = errors.New("email address is already in use")
+
+// UserWriter reads and changes users. Each change is written together with the
+// verification token, audit entry and events that go with it. Users that do not exist
+// give sql.ErrNoRows, and addresses that are taken give ErrDuplicateEmail. Operations
+// stop when their context ends, with ErrCanceled or ErrTimeout.
+type UserWriter interface {
+	GetUserByID(ctx context.Context, id string) (*models.User, error)
+	CreateUser(ctx context.Context, user *models.User, verification *models.EmailVerificationToken, audit *models.AuditEntry, events ...*models.UserEvent) error
+	UpdateUser(ctx context.Context, user *models.User, verification *models.EmailVerificationToken, audit *models.AuditEntry, events ...*models.UserEvent) error
+	DeleteUser(ctx context.Context, id string, audit *models.AuditEntry) error
+}
+
+// UserStore stores users and the history of their profiles. UserRepository keeps them
+// in Postgres and MemoryUserStore in memory.
+type UserStore interface {
+	UserWriter
+	GetUsers(ctx context.Context) ([]models.User, error)
+	GetUsersByIDs(ctx context.Context, ids []string) ([]models.User, error)
+	GetUserAsOf(ctx context.Context, id string, asOf time.Time) (*models.User, error)
+	GetUserHistory(ctx context.Context, id string, limit, offset int) ([]models.UserVersion, error)
+	ExportUsers(ctx context.Context, fn func(user *models.User) error) error
+	// InTx runs fn with a UserWriter whose changes are applied together when fn returns
+	// nil, and not at all otherwise
+	InTx(ctx context.Context, fn func(tx UserWriter) error) error
+}
+
+var (
+	_ UserStore  = (*UserRepository)(nil)
+	_ UserWriter = (*UserTx)(nil)
+	_ UserStore  = (*MemoryUserStore)(nil)
+)
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// VerificationRepository handles database operations for email verification tokens
+type VerificationRepository struct {
+	db *sqlx.DB
+}
+
+// NewVerificationRepository creates a new VerificationRepository
+func NewVerificationRepository(db *sqlx.DB) *VerificationRepository {
+	return &VerificationRepository{
+		db: db,
+	}
+}
+
+// CreateToken replaces the user's outstanding verification tokens with a new one and
+// records the event that delivers it, in one transaction
+func (r *VerificationRepository) CreateToken(token *models.EmailVerificationToken, event *models.UserEvent) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to create verification token: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertVerificationToken(tx, token); err != nil {
+		return err
+	}
+	if err := insertEvents(tx, []*models.UserEvent{event}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create verification token: %w", err)
+	}
+	return nil
+}
+
+// GetLastTokenTime gets when the user's newest outstanding verification token was
+// issued, or nil if there is none
+func (r *VerificationRepository) GetLastTokenTime(userID string) (*time.Time, error) {
+	var issuedAt *time.Time
+	query := `SELECT MAX(created_at) FROM email_verification_tokens WHERE user_id = $1 AND used_at IS NULL`
+	if err := r.db.Get(&issuedAt, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get verification token: %w", err)
+	}
+	return issuedAt, nil
+}
+
+// ConsumeToken marks an unused, unexpired token as used and the address it was issued
+// for as verified. It returns sql.ErrNoRows when the token is invalid or the user's
+// address has changed since it was issued.
+func (r *VerificationRepository) ConsumeToken(hash string) (*models.User, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+	defer tx.Rollback()
+
+	var token models.EmailVerificationToken
+	query := `
+		UPDATE email_verification_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING *
+	`
+	if err := tx.Get(&token, query, hash); err != nil {
+		return nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	var user models.User
+	update := `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, NOW())
+		WHERE id = $1 AND email = $2
+		RETURNING *
+	`
+	if err := tx.Get(&user, update, token.UserID, token.Email); err != nil {
+		return nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+	return &user, nil
+}
+
+// insertVerificationToken invalidates the user's unused tokens and stores a new one as
+// part of a transaction
+func insertVerificationToken(tx *sqlx.Tx, token *models.EmailVerificationToken) error {
+	if _, err := tx.Exec(`DELETE FROM email_verification_tokens WHERE user_id = $1 AND used_at IS NULL`, token.UserID); err != nil {
+		return fmt.Errorf("failed to invalidate verification tokens: %w", err)
+	}
+
+	query := `
+		INSERT INTO email_verification_tokens (token_hash, user_id, email, expires_at, created_at)
+		VALUES (:token_hash, :user_id, :email, :expires_at, :created_at)
+	`
+	if _, err := tx.NamedExec(query, token); err != nil {
+		return fmt.Errorf("failed to create verification token: %w", err)
+	}
+	return nil
+}
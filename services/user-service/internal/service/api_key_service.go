@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/go-microservices/user-service/internal/auth"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+)
+
+// ErrExpiryInPast is returned when an API key would expire before it is issued
+var ErrExpiryInPast = errors.New("expires_at must be in the future")
+
+// APIKeyService handles API keys
+type APIKeyService struct {
+	repo *repository.APIKeyRepository
+}
+
+// NewAPIKeyService creates a new APIKeyService
+func NewAPIKeyService(repo *repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		repo: repo,
+	}
+}
+
+// Create issues an API key for the user. The returned key is not stored and cannot be
+// shown again.
+func (s *APIKeyService) Create(userID string, req *models.CreateAPIKeyRequest, ip string) (*models.CreatedAPIKey, error) {
+	now := time.Now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, ErrExpiryInPast
+	}
+
+	plain, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	key := models.APIKey{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Name:       req.Name,
+		Prefix:     prefix,
+		SecretHash: hash,
+		Scopes:     req.Scopes,
+		ExpiresAt:  req.ExpiresAt,
+		CreatedAt:  now,
+	}
+	audit := newAuditEntry(userID, models.AuditAPIKeyCreated, userID, ip, models.AuditMetadata{"key_id": key.ID, "prefix": prefix})
+	if err := s.repo.CreateAPIKey(&key, audit); err != nil {
+		return nil, err
+	}
+
+	return &models.CreatedAPIKey{APIKey: key, Key: plain}, nil
+}
+
+// List gets a user's API keys
+func (s *APIKeyService) List(userID string) ([]models.APIKey, error) {
+	return s.repo.GetAPIKeys(userID)
+}
+
+// Revoke revokes one of a user's API keys
+func (s *APIKeyService) Revoke(userID, id, ip string) error {
+	audit := newAuditEntry(userID, models.AuditAPIKeyRevoked, userID, ip, models.AuditMetadata{"key_id": id})
+	return s.repo.RevokeAPIKey(userID, id, audit)
+}
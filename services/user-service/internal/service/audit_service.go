@@ -0,0 +1,28 @@
+package service
+
+import (
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+)
+
+// AuditService handles reading and verifying the audit log
+type AuditService struct {
+	repo *repository.AuditRepository
+}
+
+// NewAuditService creates a new AuditService
+func NewAuditService(repo *repository.AuditRepository) *AuditService {
+	return &AuditService{
+		repo: repo,
+	}
+}
+
+// List gets a page of the audit entries matching filter, newest first
+func (s *AuditService) List(filter *models.AuditFilter, limit, offset int) ([]models.AuditEntry, error) {
+	return s.repo.GetAuditEntries(filter, limit, offset)
+}
+
+// Verify checks that no audit entry has been altered or removed since it was written
+func (s *AuditService) Verify() (*models.AuditVerification, error) {
+	return s.repo.VerifyChain()
+}
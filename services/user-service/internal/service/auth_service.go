@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/go-microservices/user-service/internal/auth"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrInvalidCredentials is returned when an email and password do not match an account
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrEmailNotVerified is returned when tokens are requested for an unverified address
+	// while verification is required
+	ErrEmailNotVerified = errors.New("email not verified")
+)
+
+const (
+	// mfaChallengeTTL is how long a user has to enter their code after their password
+	mfaChallengeTTL = 5 * time.Minute
+	// mfaMaxAttempts is the number of codes that can be tried against one challenge
+	mfaMaxAttempts = 5
+)
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// AuthService handles password login and token issuance
+type AuthService struct {
+	users                *repository.UserRepository
+	tokens               *repository.TokenRepository
+	sessions             *repository.SessionRepository
+	mfa                  *repository.MFARepository
+	lockout              *LockoutService
+	issuer               *auth.TokenIssuer
+	refreshTTL           time.Duration
+	requireVerifiedEmail bool
+}
+
+// NewAuthService creates a new AuthService. With requireVerifiedEmail set, users whose
+// address is unverified cannot log in or refresh their tokens.
+func NewAuthService(users *repository.UserRepository, tokens *repository.TokenRepository, sessions *repository.SessionRepository, mfa *repository.MFARepository, lockout *LockoutService, issuer *auth.TokenIssuer, refreshTTL time.Duration, requireVerifiedEmail bool) *AuthService {
+	return &AuthService{
+		users:                users,
+		tokens:               tokens,
+		sessions:             sessions,
+		mfa:                  mfa,
+		lockout:              lockout,
+		issuer:               issuer,
+		refreshTTL:           refreshTTL,
+		requireVerifiedEmail: requireVerifiedEmail,
+	}
+}
+
+// Login checks a user's password, starts a session and issues an access and a refresh
+// token for it. Users with MFA
+// enabled get a challenge instead, to be answered with VerifyMFA. Repeated failures for
+// an address or from an IP slow down and then lock out further attempts.
+func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, client models.Client) (*models.TokenResponse, *models.MFAChallengeResponse, error) {
+	if err := s.lockout.Check(req.Email, client.IP); err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.users.GetUserByEmail(ctx, req.Email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, err
+	}
+
+	if user == nil || user.PasswordHash == nil {
+		// Spend the same time as a real check so response times do not reveal accounts
+		dummyHashOnce.Do(func() {
+			dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+		})
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(req.Password))
+		return nil, nil, s.fail(req.Email, client.IP, "invalid_credentials", ErrInvalidCredentials)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(*user.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, nil, s.fail(req.Email, client.IP, "invalid_credentials", ErrInvalidCredentials)
+	}
+
+	if err := s.checkVerified(user); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if user.MFAEnabledAt != nil {
+		challenge, err := s.challenge(user, client.IP, now)
+		return nil, challenge, err
+	}
+
+	session, refresh, plain, err := s.newSession(user, req.Device, client, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.sessions.CreateSession(session, refresh); err != nil {
+		return nil, nil, err
+	}
+	if err := s.lockout.Succeed(req.Email); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.respond(user, session.ID, plain, now)
+	return tokens, nil, err
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token. The
+// old refresh token stops working.
+func (s *AuthService) Refresh(ctx context.Context, token string, client models.Client) (*models.TokenResponse, error) {
+	// The user is checked before rotating, so that a refused refresh keeps its token
+	userID, err := s.tokens.GetRefreshTokenUserID(auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkVerified(user); err != nil {
+		return nil, err
+	}
+
+	plain, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	next := &models.RefreshToken{
+		ID:        uuid.New().String(),
+		TokenHash: hash,
+		ExpiresAt: now.Add(s.refreshTTL),
+		CreatedAt: now,
+	}
+	if err := s.tokens.RotateRefreshToken(auth.HashToken(token), next, client); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return s.respond(user, next.SessionID, plain, now)
+}
+
+// VerifyMFA answers the challenge issued by Login with a TOTP code or a recovery code and
+// starts a session. Each code works once, and a challenge allows a
+// limited number of wrong answers.
+func (s *AuthService) VerifyMFA(req *models.VerifyMFARequest, client models.Client) (*models.TokenResponse, error) {
+	tokenHash := auth.HashToken(req.MFAToken)
+	user, err := s.mfa.GetChallengeUser(tokenHash, mfaMaxAttempts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if user.MFAEnabledAt == nil || user.MFASecret == nil {
+		// MFA was reset after the challenge was issued
+		return nil, ErrInvalidToken
+	}
+	if err := s.lockout.Check(user.Email, client.IP); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var step int64
+	var recoveryHash string
+	success := newAuditEntry(user.ID, models.AuditMFAVerified, user.ID, client.IP, nil)
+	if req.RecoveryCode != "" {
+		recoveryHash = auth.HashRecoveryCode(req.RecoveryCode)
+		success.Action = models.AuditMFARecoveryCodeUsed
+	} else {
+		var lastStep int64
+		if user.MFALastStep != nil {
+			lastStep = *user.MFALastStep
+		}
+		step, _ = auth.ValidateTOTP(*user.MFASecret, req.Code, now, lastStep)
+	}
+	failure := newAuditEntry("", models.AuditMFAFailed, user.ID, client.IP, nil)
+
+	session, refresh, plain, err := s.newSession(user, req.Device, client, now)
+	if err != nil {
+		return nil, err
+	}
+	accepted, err := s.mfa.CompleteChallenge(tokenHash, mfaMaxAttempts, step, recoveryHash, session, refresh, success, failure)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if !accepted {
+		return nil, s.fail(user.Email, client.IP, "invalid_mfa_code", ErrInvalidMFACode)
+	}
+	if err := s.lockout.Succeed(user.Email); err != nil {
+		return nil, err
+	}
+
+	return s.respond(user, session.ID, plain, now)
+}
+
+// Logout revokes a refresh token and ends its session
+func (s *AuthService) Logout(token string) error {
+	return s.tokens.RevokeRefreshToken(auth.HashToken(token), time.Now())
+}
+
+// challenge issues the token that links the password step of a login to the MFA step
+func (s *AuthService) challenge(user *models.User, ip string, now time.Time) (*models.MFAChallengeResponse, error) {
+	plain, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	challenge := &models.MFAChallenge{
+		TokenHash: hash,
+		UserID:    user.ID,
+		ExpiresAt: now.Add(mfaChallengeTTL),
+		CreatedAt: now,
+	}
+	audit := newAuditEntry(user.ID, models.AuditMFAChallengeIssued, user.ID, ip, nil)
+	if err := s.mfa.CreateChallenge(challenge, audit); err != nil {
+		return nil, err
+	}
+
+	return &models.MFAChallengeResponse{
+		MFARequired: true,
+		MFAToken:    plain,
+		ExpiresIn:   int(mfaChallengeTTL.Seconds()),
+	}, nil
+}
+
+// fail records a failed login and returns err, or the error of recording it
+func (s *AuthService) fail(email, ip, reason string, err error) error {
+	if lockoutErr := s.lockout.Fail(email, ip, reason); lockoutErr != nil {
+		return lockoutErr
+	}
+	return err
+}
+
+func (s *AuthService) checkVerified(user *models.User) error {
+	if s.requireVerifiedEmail && user.EmailVerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// newSession prepares a session for the user and its first refresh token
+func (s *AuthService) newSession(user *models.User, device string, client models.Client, now time.Time) (*models.Session, *models.RefreshToken, string, error) {
+	plain, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	session := &models.Session{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		Device:     device,
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+	refresh := &models.RefreshToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		SessionID: session.ID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(s.refreshTTL),
+		CreatedAt: now,
+	}
+	return session, refresh, plain, nil
+}
+
+func (s *AuthService) respond(user *models.User, sessionID, refreshToken string, now time.Time) (*models.TokenResponse, error) {
+	access, err := s.issuer.Issue(user, sessionID, now)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TokenResponse{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.issuer.TTL().Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
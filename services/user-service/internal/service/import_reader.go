@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+)
+
+// maxImportLine bounds the length of an NDJSON line
+const maxImportLine = 1 << 20
+
+// importRow is a row of an import file. Err is set when the row cannot be read.
+type importRow struct {
+	Number int
+	Req    models.CreateUserRequest
+	Err    error
+}
+
+// rowReader reads the rows of an import file one at a time. Next returns io.EOF after
+// the last row, and any other error when the rest of the file cannot be read.
+type rowReader interface {
+	Next() (*importRow, error)
+}
+
+// newRowReader returns a reader for an import file in format
+func newRowReader(format string, r io.Reader) (rowReader, error) {
+	switch format {
+	case models.ImportCSV:
+		return newCSVReader(r)
+	case models.ImportNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxImportLine)
+		return &ndjsonReader{scanner: scanner}, nil
+	default:
+		return nil, ErrInvalidImportFormat
+	}
+}
+
+// csvReader reads CSV files with a header row naming the email, name and optional
+// password columns, in any order
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("file is empty")
+		}
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "email", "name", "password":
+		default:
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"email", "name"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvReader) Next() (*importRow, error) {
+	record, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	r.row++
+	row := &importRow{Number: r.row}
+
+	// Rows with the wrong number of fields still come with the fields they have
+	var parseErr *csv.ParseError
+	if err != nil {
+		if !errors.As(err, &parseErr) {
+			return nil, err
+		}
+		row.Err = parseErr.Err
+	}
+
+	field := func(name string) string {
+		if i, ok := r.columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	row.Req = models.CreateUserRequest{
+		Email:    field("email"),
+		Name:     field("name"),
+		Password: field("password"),
+	}
+	return row, nil
+}
+
+// ndjsonReader reads files with one JSON CreateUserRequest per line. Rows are numbered
+// by line; blank lines are skipped.
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *ndjsonReader) Next() (*importRow, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		row := &importRow{Number: r.line}
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row.Req); err != nil {
+			row.Err = fmt.Errorf("invalid JSON: %w", err)
+		}
+		return row, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", r.line+1, err)
+	}
+	return nil, io.EOF
+}
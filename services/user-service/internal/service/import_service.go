@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// importBatchSize is how many rows are written per transaction
+	importBatchSize = 500
+	// staleImportAfter is how long a job may go without progress before it is considered
+	// lost with the replica that ran it
+	staleImportAfter = 10 * time.Minute
+)
+
+// ErrInvalidImportFormat is returned for import files that are neither CSV nor NDJSON
+var ErrInvalidImportFormat = errors.New("format must be csv or ndjson")
+
+// ImportService runs bulk user imports in the background. Uploads are spooled to a
+// temporary file, so the request returns as soon as the file has arrived.
+type ImportService struct {
+	repo         *repository.ImportRepository
+	verification *VerificationService
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewImportService creates a new ImportService
+func NewImportService(repo *repository.ImportRepository, verification *VerificationService) *ImportService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ImportService{
+		repo:         repo,
+		verification: verification,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Start stores the file read from body and starts importing it. Valid rows create new
+// users, as CreateUser does, or update the name of the user with the same address.
+// With dryRun, rows are only validated.
+func (s *ImportService) Start(format string, dryRun bool, body io.Reader, actor models.Actor) (*models.ImportJob, error) {
+	if format != models.ImportCSV && format != models.ImportNDJSON {
+		return nil, ErrInvalidImportFormat
+	}
+
+	file, err := os.CreateTemp("", "user-import-*")
+	if err != nil {
+		return nil, err
+	}
+	size, err := io.Copy(file, body)
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &models.ImportJob{
+		ID:        uuid.New().String(),
+		Status:    models.ImportPending,
+		Format:    format,
+		DryRun:    dryRun,
+		SizeBytes: size,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if actor.UserID != "" {
+		job.CreatedBy = &actor.UserID
+	}
+	if err := s.repo.CreateImportJob(job); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer os.Remove(file.Name())
+		defer file.Close()
+
+		// The job runs on its own copy, so callers can keep the returned one
+		job := *job
+		s.run(&job, file, actor)
+	}()
+	return job, nil
+}
+
+// Get gets an import job. Jobs that stopped making progress, because the replica
+// running them went away, are marked as failed.
+func (s *ImportService) Get(id string) (*models.ImportJob, error) {
+	job, err := s.repo.GetImportJob(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.FinishedAt == nil && time.Since(job.UpdatedAt) > staleImportAfter {
+		s.finish(job, errors.New("import was interrupted"))
+	}
+	return job, nil
+}
+
+// Errors gets the row errors of an import job
+func (s *ImportService) Errors(id string) ([]models.ImportRowError, error) {
+	if _, err := s.repo.GetImportJob(id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetImportErrors(id)
+}
+
+// Stop interrupts running imports after their current batch and waits for them
+func (s *ImportService) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// run imports the rows of file in batches, saving the job's progress and row errors
+// after each batch
+func (s *ImportService) run(job *models.ImportJob, file *os.File, actor models.Actor) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		s.finish(job, err)
+		return
+	}
+	counter := &countingReader{reader: file}
+	rows, err := newRowReader(job.Format, counter)
+	if err != nil {
+		s.finish(job, err)
+		return
+	}
+
+	job.Status = models.ImportRunning
+	if err := s.save(job, nil); err != nil {
+		return
+	}
+
+	// Row that first used each address, to reject repeats within the file
+	seen := map[string]int{}
+	var batch []*importRow
+	var rowErrors []models.ImportRowError
+	reject := func(row *importRow, err error) {
+		job.FailedRows++
+		rowErrors = append(rowErrors, models.ImportRowError{
+			JobID:     job.ID,
+			RowNumber: row.Number,
+			Email:     row.Req.Email,
+			Error:     err.Error(),
+		})
+	}
+	flush := func() error {
+		if len(batch) > 0 && !job.DryRun {
+			created, updated, err := s.upsert(job.ID, batch, actor)
+			if err != nil {
+				for _, row := range batch {
+					reject(row, fmt.Errorf("batch failed: %w", err))
+				}
+			}
+			job.CreatedRows += created
+			job.UpdatedRows += updated
+		}
+		batch = nil
+
+		job.BytesRead = counter.n
+		err := s.save(job, rowErrors)
+		rowErrors = nil
+		return err
+	}
+
+	for {
+		row, err := rows.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			s.finish(job, err)
+			return
+		}
+
+		job.ProcessedRows++
+		if row.Err != nil {
+			reject(row, row.Err)
+			continue
+		}
+		if err := validateRequest(&row.Req); err != nil {
+			reject(row, err)
+			continue
+		}
+		if first, ok := seen[row.Req.Email]; ok {
+			reject(row, fmt.Errorf("email already used in row %d", first))
+			continue
+		}
+		seen[row.Req.Email] = row.Number
+
+		batch = append(batch, row)
+		if len(batch) < importBatchSize {
+			continue
+		}
+		if err := flush(); err != nil {
+			return
+		}
+		if s.ctx.Err() != nil {
+			s.finish(job, errors.New("import was interrupted by a shutdown"))
+			return
+		}
+	}
+
+	if err := flush(); err != nil {
+		return
+	}
+	s.finish(job, nil)
+}
+
+// upsert writes a batch of valid rows and returns how many users were created and updated
+func (s *ImportService) upsert(jobID string, batch []*importRow, actor models.Actor) (int, int, error) {
+	// Hash passwords before the transaction locks any users; bcrypt is slow on purpose
+	emails := make([]string, len(batch))
+	hashes := make([]*string, len(batch))
+	for i, row := range batch {
+		emails[i] = row.Req.Email
+		if row.Req.Password == "" {
+			continue
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(row.Req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return 0, 0, err
+		}
+		passwordHash := string(hash)
+		hashes[i] = &passwordHash
+	}
+
+	upsert, err := s.repo.UpsertUsers(emails, func(users map[string]*models.User) (*models.UserUpsert, error) {
+		now := time.Now()
+		upsert := &models.UserUpsert{}
+		metadata := models.AuditMetadata{"import_job": jobID}
+
+		for i, row := range batch {
+			if existing, ok := users[row.Req.Email]; ok {
+				// Existing users keep their password; only the name is imported
+				if existing.Name == row.Req.Name {
+					continue
+				}
+				before := *existing
+				existing.Name = row.Req.Name
+				existing.UpdatedAt = now
+
+				audit, err := newUserAuditEntry(actor, models.AuditUserUpdated, existing.ID, &before, existing)
+				if err != nil {
+					return nil, err
+				}
+				audit.Metadata = metadata
+				upsert.Update = append(upsert.Update, existing)
+				upsert.Audit = append(upsert.Audit, audit)
+				continue
+			}
+
+			user := &models.User{
+				ID:           uuid.New().String(),
+				Email:        row.Req.Email,
+				Name:         row.Req.Name,
+				Role:         models.RoleUser,
+				PasswordHash: hashes[i],
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			}
+			verification, verificationEvent, err := s.verification.prepare(user, now)
+			if err != nil {
+				return nil, err
+			}
+			audit, err := newUserAuditEntry(actor, models.AuditUserCreated, user.ID, nil, user)
+			if err != nil {
+				return nil, err
+			}
+			audit.Metadata = metadata
+
+			upsert.Create = append(upsert.Create, user)
+			upsert.Verifications = append(upsert.Verifications, verification)
+			upsert.Audit = append(upsert.Audit, audit)
+			upsert.Events = append(upsert.Events, &models.UserEvent{
+				ID:        uuid.New().String(),
+				Type:      models.EventUserCreated,
+				UserID:    user.ID,
+				Data:      models.EventData{Email: user.Email, Name: user.Name},
+				CreatedAt: now,
+			}, verificationEvent)
+		}
+		return upsert, nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(upsert.Create), len(upsert.Update), nil
+}
+
+// finish marks a job as completed, or as failed with err
+func (s *ImportService) finish(job *models.ImportJob, err error) {
+	now := time.Now()
+	job.Status = models.ImportCompleted
+	if err != nil {
+		message := err.Error()
+		job.Status = models.ImportFailed
+		job.Error = &message
+	}
+	job.FinishedAt = &now
+	s.save(job, nil)
+}
+
+// save stores a job's progress. A job whose progress cannot be stored is abandoned; it
+// is marked as failed once it goes stale.
+func (s *ImportService) save(job *models.ImportJob, rowErrors []models.ImportRowError) error {
+	job.UpdatedAt = time.Now()
+	return s.repo.UpdateImportJob(job, rowErrors)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
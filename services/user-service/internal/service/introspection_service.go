@@ -0,0 +1,94 @@
+package service
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/yourusername/go-microservices/user-service/internal/auth"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+)
+
+// IntrospectionService resolves API keys and access tokens for the gateway
+type IntrospectionService struct {
+	apiKeys  *repository.APIKeyRepository
+	sessions *repository.SessionRepository
+	issuer   *auth.TokenIssuer
+}
+
+// NewIntrospectionService creates a new IntrospectionService
+func NewIntrospectionService(apiKeys *repository.APIKeyRepository, sessions *repository.SessionRepository, issuer *auth.TokenIssuer) *IntrospectionService {
+	return &IntrospectionService{
+		apiKeys:  apiKeys,
+		sessions: sessions,
+		issuer:   issuer,
+	}
+}
+
+// Introspect resolves a credential to the identity behind it. Unknown, revoked and
+// expired credentials, and access tokens whose session has ended, are reported as
+// inactive rather than as errors.
+func (s *IntrospectionService) Introspect(token string) (*models.Introspection, error) {
+	if strings.HasPrefix(token, auth.APIKeyPrefix) {
+		return s.introspectAPIKey(token)
+	}
+	return s.introspectAccessToken(token)
+}
+
+func (s *IntrospectionService) introspectAPIKey(token string) (*models.Introspection, error) {
+	inactive := &models.Introspection{Active: false}
+
+	prefix, secret, ok := auth.ParseAPIKey(token)
+	if !ok {
+		return inactive, nil
+	}
+	key, role, err := s.apiKeys.GetActiveAPIKey(prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return inactive, nil
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(auth.HashToken(secret)), []byte(key.SecretHash)) != 1 {
+		return inactive, nil
+	}
+
+	if err := s.apiKeys.TouchAPIKey(key.ID); err != nil {
+		return nil, err
+	}
+	return &models.Introspection{
+		Active:    true,
+		UserID:    key.UserID,
+		Role:      role,
+		Scopes:    key.Scopes,
+		KeyID:     key.ID,
+		ExpiresAt: key.ExpiresAt,
+	}, nil
+}
+
+func (s *IntrospectionService) introspectAccessToken(token string) (*models.Introspection, error) {
+	inactive := &models.Introspection{Active: false}
+
+	claims, err := s.issuer.Parse(token)
+	if err != nil || claims.SessionID == "" {
+		return inactive, nil
+	}
+	active, err := s.sessions.IsSessionActive(claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return inactive, nil
+	}
+
+	expiresAt := claims.ExpiresAt.Time
+	return &models.Introspection{
+		Active:    true,
+		UserID:    claims.Subject,
+		Role:      claims.Role,
+		SessionID: claims.SessionID,
+		ExpiresAt: &expiresAt,
+	}, nil
+}
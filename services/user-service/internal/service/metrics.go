@@ -0,0 +1,24 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	loginFailuresTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "user_login_failures_total",
+			Help: "Total number of failed login attempts by reason",
+		},
+		[]string{"reason"},
+	)
+
+	loginLockoutsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "user_login_lockouts_total",
+			Help: "Total number of temporary login lockouts by scope",
+		},
+		[]string{"scope"},
+	)
+)
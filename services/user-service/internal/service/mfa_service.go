@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/yourusername/go-microservices/user-service/internal/auth"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+)
+
+// recoveryCodeCount is the number of recovery codes generated on enrollment
+const recoveryCodeCount = 10
+
+var (
+	// ErrMFAAlreadyEnabled is returned when enrolling a user who already has MFA enabled
+	ErrMFAAlreadyEnabled = errors.New("MFA already enabled")
+	// ErrMFANotPending is returned when confirming without a pending enrollment
+	ErrMFANotPending = errors.New("no pending MFA enrollment")
+	// ErrInvalidMFACode is returned when a TOTP or recovery code is wrong or already used
+	ErrInvalidMFACode = errors.New("invalid MFA code")
+)
+
+// MFAService handles TOTP enrollment and admin resets
+type MFAService struct {
+	users  *repository.UserRepository
+	repo   *repository.MFARepository
+	issuer string
+}
+
+// NewMFAService creates a new MFAService. Authenticator apps list accounts under issuer.
+func NewMFAService(users *repository.UserRepository, repo *repository.MFARepository, issuer string) *MFAService {
+	return &MFAService{
+		users:  users,
+		repo:   repo,
+		issuer: issuer,
+	}
+}
+
+// Enroll starts an enrollment with a new TOTP secret. MFA stays off until Confirm is
+// called with a code generated from the secret.
+func (s *MFAService) Enroll(ctx context.Context, userID, ip string) (*models.MFAEnrollment, error) {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	audit := newAuditEntry(userID, models.AuditMFAEnrollmentStarted, userID, ip, nil)
+	if err := s.repo.StartEnrollment(userID, secret, audit); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	return &models.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: auth.ProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables MFA when the code matches the pending secret and returns the user's
+// recovery codes, which are not stored in readable form
+func (s *MFAService) Confirm(ctx context.Context, userID, code, ip string) (*models.RecoveryCodesResponse, error) {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == nil {
+		return nil, ErrMFANotPending
+	}
+
+	step, ok := auth.ValidateTOTP(*user.MFASecret, code, time.Now(), 0)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := auth.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	audit := newAuditEntry(userID, models.AuditMFAEnabled, userID, ip, nil)
+	if err := s.repo.EnableMFA(userID, step, hashes, audit); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMFANotPending
+		}
+		return nil, err
+	}
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Reset turns MFA off for a user on behalf of an admin, for example after they lost
+// their authenticator and recovery codes
+func (s *MFAService) Reset(adminID, userID, ip string) error {
+	audit := newAuditEntry(adminID, models.AuditMFAReset, userID, ip, nil)
+	return s.repo.ResetMFA(userID, audit)
+}
+
+// newAuditEntry builds an audit log entry. Empty actor, target and IP are left unset.
+func newAuditEntry(actorID, action, targetID, ip string, metadata models.AuditMetadata) *models.AuditEntry {
+	entry := &models.AuditEntry{
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+	if actorID != "" {
+		entry.ActorID = &actorID
+	}
+	if targetID != "" {
+		entry.TargetID = &targetID
+	}
+	if ip != "" {
+		entry.IP = &ip
+	}
+	return entry
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/go-microservices/user-service/internal/auth"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// resetWindow is the period over which reset requests are rate limited
+	resetWindow = time.Hour
+	// resetEmailLimit caps reset requests for one address per window, so a mailbox cannot be flooded
+	resetEmailLimit = 3
+	// resetIPLimit caps reset requests from one client per window, so addresses cannot be probed in bulk
+	resetIPLimit = 20
+)
+
+// ErrTooManyRequests is returned when a client exceeds a rate limit
+var ErrTooManyRequests = errors.New("too many requests")
+
+// PasswordResetService handles self-service password resets
+type PasswordResetService struct {
+	users  *repository.UserRepository
+	repo   *repository.PasswordResetRepository
+	ttl    time.Duration
+	appURL string
+}
+
+// NewPasswordResetService creates a new PasswordResetService. Reset links point to the
+// reset-password page of the application at appURL and expire after ttl.
+func NewPasswordResetService(users *repository.UserRepository, repo *repository.PasswordResetRepository, ttl time.Duration, appURL string) *PasswordResetService {
+	return &PasswordResetService{
+		users:  users,
+		repo:   repo,
+		ttl:    ttl,
+		appURL: appURL,
+	}
+}
+
+// Request sends a reset link to the address if it belongs to an account. The result is
+// the same whether or not it does, so callers cannot use it to discover accounts.
+func (s *PasswordResetService) Request(ctx context.Context, email, ip string) error {
+	// Rate limits count addresses case-insensitively
+	key := strings.ToLower(strings.TrimSpace(email))
+	now := time.Now()
+
+	byEmail, byIP, err := s.repo.CountRequests(key, ip, now.Add(-resetWindow))
+	if err != nil {
+		return err
+	}
+	if byEmail >= resetEmailLimit || byIP >= resetIPLimit {
+		return ErrTooManyRequests
+	}
+
+	user, err := s.users.GetUserByEmail(ctx, email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if user == nil {
+		return s.repo.RecordRequest(key, ip, nil, nil, resetWindow)
+	}
+
+	plain, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+	token := &models.PasswordResetToken{
+		TokenHash: hash,
+		UserID:    user.ID,
+		ExpiresAt: now.Add(s.ttl),
+		CreatedAt: now,
+	}
+	event := &models.UserEvent{
+		ID:     uuid.New().String(),
+		Type:   models.EventPasswordResetRequested,
+		UserID: user.ID,
+		Data: models.EventData{
+			Email: user.Email,
+			Name:  user.Name,
+			Link:  s.appURL + "/reset-password?token=" + url.QueryEscape(plain),
+		},
+		CreatedAt: now,
+	}
+	return s.repo.RecordRequest(key, ip, token, event, resetWindow)
+}
+
+// Confirm consumes a reset token and sets the user's new password. Every refresh token
+// of the user is revoked, signing out all of their sessions.
+func (s *PasswordResetService) Confirm(req *models.ConfirmPasswordResetRequest) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.repo.ResetPassword(auth.HashToken(req.Token), string(hash)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+	return nil
+}
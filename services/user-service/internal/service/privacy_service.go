@@ -0,0 +1,87 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/notifications"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+)
+
+// ErasedName replaces the name of erased users
+const ErasedName = "Erased user"
+
+// ErrUserErased is returned when changing a user whose personal data has been erased
+var ErrUserErased = errors.New("user has been erased")
+
+// PrivacyService handles data export and erasure requests
+type PrivacyService struct {
+	repo          *repository.PrivacyRepository
+	notifications *notifications.Client
+}
+
+// NewPrivacyService creates a new PrivacyService. notifications may be nil when the
+// notification service is not configured, in which case exports leave out its data.
+func NewPrivacyService(repo *repository.PrivacyRepository, notifications *notifications.Client) *PrivacyService {
+	return &PrivacyService{
+		repo:          repo,
+		notifications: notifications,
+	}
+}
+
+// Export assembles everything held about a user, including the notification service's
+// data about them
+func (s *PrivacyService) Export(userID string, actor models.Actor) (*models.UserExport, error) {
+	audit := newAuditEntry(actor.UserID, models.AuditUserExported, userID, actor.IP, nil)
+	setRequestID(audit, actor)
+
+	// Fetch the remote part first so that a failed export is not recorded as done
+	var notificationData []byte
+	if s.notifications != nil {
+		data, err := s.notifications.GetUserData(userID)
+		if err != nil {
+			return nil, err
+		}
+		notificationData = data
+	}
+
+	export, err := s.repo.GetUserExport(userID, audit)
+	if err != nil {
+		return nil, err
+	}
+	export.ExportedAt = audit.CreatedAt
+	export.Notifications = notificationData
+	return export, nil
+}
+
+// Erase anonymizes a user in place and publishes a user.erased event, so that other
+// services delete their copies of the user's data. Erasing an erased user changes nothing
+// and returns the time of the first erasure.
+func (s *PrivacyService) Erase(userID string, actor models.Actor) (*models.Erasure, error) {
+	now := time.Now()
+
+	// An erasure requested by the user must not record their IP again
+	ip := actor.IP
+	if actor.UserID == userID {
+		ip = ""
+	}
+	audit := newAuditEntry(actor.UserID, models.AuditUserErased, userID, ip, nil)
+	setRequestID(audit, actor)
+
+	event := &models.UserEvent{
+		ID:        uuid.New().String(),
+		Type:      models.EventUserErased,
+		UserID:    userID,
+		CreatedAt: now,
+	}
+
+	return s.repo.EraseUser(userID, erasedEmail(userID), ErasedName, now, audit, event)
+}
+
+// erasedEmail is the unique placeholder address of an erased user. The .invalid domain
+// cannot receive mail.
+func erasedEmail(userID string) string {
+	return "erased-" + userID + "@erased.invalid"
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+)
+
+// SessionService handles listing and revoking sessions
+type SessionService struct {
+	repo *repository.SessionRepository
+}
+
+// NewSessionService creates a new SessionService
+func NewSessionService(repo *repository.SessionRepository) *SessionService {
+	return &SessionService{
+		repo: repo,
+	}
+}
+
+// List gets a user's active sessions, marking the session with ID currentID as current
+func (s *SessionService) List(userID, currentID string) ([]models.Session, error) {
+	sessions, err := s.repo.GetSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+// Revoke ends one of a user's sessions on behalf of actorID, who is the user or an admin
+func (s *SessionService) Revoke(actorID, userID, id, ip string) error {
+	audit := newAuditEntry(actorID, models.AuditSessionRevoked, userID, ip, models.AuditMetadata{"session_id": id})
+	return s.repo.RevokeSession(userID, id, audit)
+}
+
+// RevokeAll ends all of a user's sessions except exceptID, if set, on behalf of actorID.
+// It returns the number of sessions ended.
+func (s *SessionService) RevokeAll(actorID, userID, exceptID, ip string) (int, error) {
+	metadata := models.AuditMetadata{"session_id": "all"}
+	if exceptID != "" {
+		metadata["except_session_id"] = exceptID
+	}
+	audit := newAuditEntry(actorID, models.AuditSessionRevoked, userID, ip, metadata)
+	return s.repo.RevokeSessions(userID, exceptID, audit)
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+)
+
+var (
+	// ErrInvalidBatchOperation is returned for batch operations that cannot be applied as given
+	ErrInvalidBatchOperation = errors.New("invalid operation")
+	// ErrBatchAborted is the outcome of the operations of an all-or-nothing batch that were
+	// rolled back, or never tried, because another operation failed
+	ErrBatchAborted = errors.New("batch was rolled back")
+)
+
+// BatchOutcome is the outcome of one batch operation: the user it created or updated, or
+// the error it failed with
+type BatchOutcome struct {
+	User *models.User
+	Err  error
+}
+
+// GetUsersByIDs gets the users with the given IDs, in the order asked for. Repeated IDs
+// are looked up once; IDs that are not UUIDs cannot exist and are reported missing.
+func (s *UserService) GetUsersByIDs(ctx context.Context, ids []string) (*models.BatchGetResponse, error) {
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	valid := make([]string, 0, len(unique))
+	for _, id := range unique {
+		if _, err := uuid.Parse(id); err == nil {
+			valid = append(valid, id)
+		}
+	}
+	users, err := s.repo.GetUsersByIDs(ctx, valid)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	result := &models.BatchGetResponse{Found: []models.User{}, Missing: []string{}}
+	for _, id := range unique {
+		if user, ok := byID[id]; ok {
+			result.Found = append(result.Found, user)
+		} else {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+	return result, nil
+}
+
+// ApplyBatch applies batch operations in order and returns the outcome of each. Each
+// operation is applied as CreateUser, UpdateUser or DeleteUser would, in its own
+// transaction. With atomic, all operations share one transaction and the batch stops at
+// the first failure; the other operations then fail with ErrBatchAborted.
+func (s *UserService) ApplyBatch(ctx context.Context, ops []models.BatchOperation, atomic bool, actor models.Actor) []BatchOutcome {
+	outcomes := make([]BatchOutcome, len(ops))
+	if !atomic {
+		for i, op := range ops {
+			outcomes[i].User, outcomes[i].Err = s.applyOperation(ctx, s.repo, op, actor)
+		}
+		return outcomes
+	}
+
+	failed := -1
+	err := s.repo.InTx(ctx, func(tx repository.UserWriter) error {
+		for i, op := range ops {
+			outcomes[i].User, outcomes[i].Err = s.applyOperation(ctx, tx, op, actor)
+			if outcomes[i].Err != nil {
+				failed = i
+				return outcomes[i].Err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		return outcomes
+	}
+	for i := range outcomes {
+		switch {
+		case failed == -1:
+			// The commit itself failed, so no operation is to blame
+			outcomes[i] = BatchOutcome{Err: err}
+		case i != failed:
+			outcomes[i] = BatchOutcome{Err: ErrBatchAborted}
+		}
+	}
+	return outcomes
+}
+
+// applyOperation applies one batch operation to store
+func (s *UserService) applyOperation(ctx context.Context, store repository.UserWriter, op models.BatchOperation, actor models.Actor) (*models.User, error) {
+	switch op.Op {
+	case models.BatchCreate:
+		var req models.CreateUserRequest
+		if err := decodeOperation(op, &req); err != nil {
+			return nil, err
+		}
+		return s.createUser(ctx, store, &req, actor)
+	case models.BatchUpdate:
+		if err := checkOperationID(op); err != nil {
+			return nil, err
+		}
+		var req models.UpdateUserRequest
+		if err := decodeOperation(op, &req); err != nil {
+			return nil, err
+		}
+		return s.updateUser(ctx, store, op.ID, &req, actor)
+	case models.BatchDelete:
+		if err := checkOperationID(op); err != nil {
+			return nil, err
+		}
+		return nil, s.deleteUser(ctx, store, op.ID, actor)
+	default:
+		return nil, fmt.Errorf("%w: op must be create, update or delete", ErrInvalidBatchOperation)
+	}
+}
+
+// checkOperationID checks that a batch operation names a user. IDs that are not UUIDs
+// cannot exist, and give sql.ErrNoRows as they would from the database.
+func checkOperationID(op models.BatchOperation) error {
+	if op.ID == "" {
+		return fmt.Errorf("%w: id is required", ErrInvalidBatchOperation)
+	}
+	if _, err := uuid.Parse(op.ID); err != nil {
+		return fmt.Errorf("failed to get user: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// decodeOperation decodes and validates the user fields of a batch operation into req
+func decodeOperation(op models.BatchOperation, req interface{}) error {
+	if len(op.User) == 0 {
+		return fmt.Errorf("%w: user is required", ErrInvalidBatchOperation)
+	}
+	if err := json.Unmarshal(op.User, req); err != nil {
+		return fmt.Errorf("%w: user must be an object", ErrInvalidBatchOperation)
+	}
+	if err := validateRequest(req); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBatchOperation, err)
+	}
+	return nil
+}
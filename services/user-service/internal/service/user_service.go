@@ -1,60 +1,136 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"reflect"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/yourusername/go-microservices/user-service/internal/models"
 	"github.com/yourusername/go-microservices/user-service/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrDuplicateEmail is returned when creating or updating a user with an address that
+// another user already has
+var ErrDuplicateEmail = repository.ErrDuplicateEmail
+
+var (
+	// ErrCanceled is returned when the request's context was canceled during a query
+	ErrCanceled = repository.ErrCanceled
+	// ErrTimeout is returned when a query ran past the request's deadline or the
+	// database's statement timeout
+	ErrTimeout = repository.ErrTimeout
 )
 
 // UserService handles business logic for users
 type UserService struct {
-	repo *repository.UserRepository
+	repo         repository.UserStore
+	verification *VerificationService
 }
 
 // NewUserService creates a new UserService
-func NewUserService(repo *repository.UserRepository) *UserService {
+func NewUserService(repo repository.UserStore, verification *VerificationService) *UserService {
 	return &UserService{
-		repo: repo,
+		repo:         repo,
+		verification: verification,
 	}
 }
 
 // GetUsers gets all users
-func (s *UserService) GetUsers() ([]models.User, error) {
-	return s.repo.GetUsers()
+func (s *UserService) GetUsers(ctx context.Context) ([]models.User, error) {
+	return s.repo.GetUsers(ctx)
 }
 
 // GetUser gets a user by ID
-func (s *UserService) GetUser(id string) (*models.User, error) {
-	return s.repo.GetUserByID(id)
+func (s *UserService) GetUser(ctx context.Context, id string) (*models.User, error) {
+	return s.repo.GetUserByID(ctx, id)
+}
+
+// GetUserAsOf gets a user as they were at asOf
+func (s *UserService) GetUserAsOf(ctx context.Context, id string, asOf time.Time) (*models.User, error) {
+	return s.repo.GetUserAsOf(ctx, id, asOf)
+}
+
+// GetUserHistory gets a page of a user's versions, newest first. Versions of deleted
+// users are kept.
+func (s *UserService) GetUserHistory(ctx context.Context, id string, limit, offset int) ([]models.UserVersion, error) {
+	return s.repo.GetUserHistory(ctx, id, limit, offset)
+}
+
+// ExportUsers passes every user to fn, oldest first, from a single snapshot
+func (s *UserService) ExportUsers(ctx context.Context, fn func(user *models.User) error) error {
+	return s.repo.ExportUsers(ctx, fn)
+}
+
+// CreateUser creates a new user with an unverified address and sends them a verification link
+func (s *UserService) CreateUser(ctx context.Context, req *models.CreateUserRequest, actor models.Actor) (*models.User, error) {
+	return s.createUser(ctx, s.repo, req, actor)
 }
 
-// CreateUser creates a new user
-func (s *UserService) CreateUser(req *models.CreateUserRequest) (*models.User, error) {
+func (s *UserService) createUser(ctx context.Context, store repository.UserWriter, req *models.CreateUserRequest, actor models.Actor) (*models.User, error) {
 	now := time.Now()
 	user := &models.User{
 		ID:        uuid.New().String(),
 		Email:     req.Email,
 		Name:      req.Name,
+		Role:      models.RoleUser,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 	
-	if err := s.repo.CreateUser(user); err != nil {
+	if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		passwordHash := string(hash)
+		user.PasswordHash = &passwordHash
+	}
+	
+	verification, verificationEvent, err := s.verification.prepare(user, now)
+	if err != nil {
+		return nil, err
+	}
+	
+	event := &models.UserEvent{
+		ID:        uuid.New().String(),
+		Type:      models.EventUserCreated,
+		UserID:    user.ID,
+		Data:      models.EventData{Email: user.Email, Name: user.Name},
+		CreatedAt: now,
+	}
+	
+	audit, err := newUserAuditEntry(actor, models.AuditUserCreated, user.ID, nil, user)
+	if err != nil {
+		return nil, err
+	}
+	
+	if err := store.CreateUser(ctx, user, verification, audit, event, verificationEvent); err != nil {
 		return nil, err
 	}
 	
 	return user, nil
 }
 
-// UpdateUser updates a user
-func (s *UserService) UpdateUser(id string, req *models.UpdateUserRequest) (*models.User, error) {
-	user, err := s.repo.GetUserByID(id)
+// UpdateUser updates a user. Changing the email address resets its verification and
+// sends a verification link to the new address.
+func (s *UserService) UpdateUser(ctx context.Context, id string, req *models.UpdateUserRequest, actor models.Actor) (*models.User, error) {
+	return s.updateUser(ctx, s.repo, id, req, actor)
+}
+
+func (s *UserService) updateUser(ctx context.Context, store repository.UserWriter, id string, req *models.UpdateUserRequest, actor models.Actor) (*models.User, error) {
+	user, err := store.GetUserByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if user.ErasedAt != nil {
+		return nil, ErrUserErased
+	}
+	before := *user
 	
+	oldEmail := user.Email
 	if req.Email != "" {
 		user.Email = req.Email
 	}
@@ -65,14 +141,108 @@ func (s *UserService) UpdateUser(id string, req *models.UpdateUserRequest) (*mod
 	
 	user.UpdatedAt = time.Now()
 	
-	if err := s.repo.UpdateUser(user); err != nil {
+	var verification *models.EmailVerificationToken
+	var events []*models.UserEvent
+	if user.Email != oldEmail {
+		user.EmailVerifiedAt = nil
+		
+		var verificationEvent *models.UserEvent
+		verification, verificationEvent, err = s.verification.prepare(user, user.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		
+		events = append(events, &models.UserEvent{
+			ID:        uuid.New().String(),
+			Type:      models.EventUserEmailChanged,
+			UserID:    user.ID,
+			Data:      models.EventData{Email: user.Email, Name: user.Name, OldEmail: oldEmail},
+			CreatedAt: user.UpdatedAt,
+		}, verificationEvent)
+	}
+	
+	audit, err := newUserAuditEntry(actor, models.AuditUserUpdated, user.ID, &before, user)
+	if err != nil {
+		return nil, err
+	}
+	
+	if err := store.UpdateUser(ctx, user, verification, audit, events...); err != nil {
 		return nil, err
 	}
 	
 	return user, nil
 }
 
-// DeleteUser deletes a user
-func (s *UserService) DeleteUser(id string) error {
-	return s.repo.DeleteUser(id)
+// DeleteUser deletes a user. It returns sql.ErrNoRows when the user does not exist.
+func (s *UserService) DeleteUser(ctx context.Context, id string, actor models.Actor) error {
+	return s.deleteUser(ctx, s.repo, id, actor)
+}
+
+func (s *UserService) deleteUser(ctx context.Context, store repository.UserWriter, id string, actor models.Actor) error {
+	user, err := store.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	
+	audit, err := newUserAuditEntry(actor, models.AuditUserDeleted, user.ID, user, nil)
+	if err != nil {
+		return err
+	}
+	
+	return store.DeleteUser(ctx, id, audit)
+}
+
+// newUserAuditEntry creates the audit entry for a change to a user, with the fields
+// that differ between before and after. Either may be nil for created and deleted users.
+// Secrets are left out because they are not part of the user's JSON form.
+func newUserAuditEntry(actor models.Actor, action, userID string, before, after *models.User) (*models.AuditEntry, error) {
+	beforeFields, err := userFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := userFields(after)
+	if err != nil {
+		return nil, err
+	}
+	
+	changes := models.AuditChanges{}
+	for _, fields := range []map[string]interface{}{beforeFields, afterFields} {
+		for name := range fields {
+			// updated_at changes every time and the entry has its own timestamp
+			if name == "updated_at" {
+				continue
+			}
+			if !reflect.DeepEqual(beforeFields[name], afterFields[name]) {
+				changes[name] = models.AuditChange{Before: beforeFields[name], After: afterFields[name]}
+			}
+		}
+	}
+	
+	entry := newAuditEntry(actor.UserID, action, userID, actor.IP, nil)
+	entry.Changes = changes
+	setRequestID(entry, actor)
+	return entry, nil
+}
+
+// setRequestID tags an audit entry with the ID of the actor's request
+func setRequestID(entry *models.AuditEntry, actor models.Actor) {
+	if actor.RequestID != "" {
+		entry.RequestID = &actor.RequestID
+	}
+}
+
+// userFields returns the fields of a user's JSON form, or nil for a nil user
+func userFields(user *models.User) (map[string]interface{}, error) {
+	if user == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
 }
\ No newline at end of file
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+)
+
+func newTestUserService() (*UserService, *repository.MemoryUserStore) {
+	store := repository.NewMemoryUserStore()
+	verification := NewVerificationService(nil, nil, time.Hour, "http://localhost")
+	return NewUserService(store, verification), store
+}
+
+func TestCreateUserRecordsAuditAndEvents(t *testing.T) {
+	s, store := newTestUserService()
+
+	user, err := s.CreateUser(context.Background(), &models.CreateUserRequest{Email: "ada@example.com", Name: "Ada"}, models.Actor{})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	entries := store.AuditEntries()
+	if len(entries) != 1 || entries[0].Action != models.AuditUserCreated || *entries[0].TargetID != user.ID {
+		t.Errorf("audit entries = %+v, want one %s entry for %s", entries, models.AuditUserCreated, user.ID)
+	}
+	var types []string
+	for _, event := range store.Events() {
+		types = append(types, event.Type)
+	}
+	if len(types) != 2 || types[0] != models.EventUserCreated || types[1] != models.EventUserVerificationRequested {
+		t.Errorf("events = %v, want %s and %s", types, models.EventUserCreated, models.EventUserVerificationRequested)
+	}
+
+	_, err = s.CreateUser(context.Background(), &models.CreateUserRequest{Email: "ada@example.com", Name: "Ada"}, models.Actor{})
+	if !errors.Is(err, ErrDuplicateEmail) {
+		t.Errorf("CreateUser with a used address: got %v, want ErrDuplicateEmail", err)
+	}
+}
+
+func TestApplyBatch(t *testing.T) {
+	create := func(email string) models.BatchOperation {
+		user, _ := json.Marshal(models.CreateUserRequest{Email: email, Name: "Batch"})
+		return models.BatchOperation{Op: models.BatchCreate, User: user}
+	}
+	ops := []models.BatchOperation{
+		create("one@example.com"),
+		create("one@example.com"),
+		{Op: "rename"},
+	}
+
+	t.Run("independent", func(t *testing.T) {
+		s, _ := newTestUserService()
+		outcomes := s.ApplyBatch(context.Background(), ops, false, models.Actor{})
+		if outcomes[0].Err != nil || outcomes[0].User == nil {
+			t.Errorf("first create: got %v, want a user", outcomes[0].Err)
+		}
+		if !errors.Is(outcomes[1].Err, ErrDuplicateEmail) {
+			t.Errorf("second create: got %v, want ErrDuplicateEmail", outcomes[1].Err)
+		}
+		if !errors.Is(outcomes[2].Err, ErrInvalidBatchOperation) {
+			t.Errorf("unknown op: got %v, want ErrInvalidBatchOperation", outcomes[2].Err)
+		}
+	})
+
+	t.Run("atomic", func(t *testing.T) {
+		s, store := newTestUserService()
+		outcomes := s.ApplyBatch(context.Background(), ops, true, models.Actor{})
+		if !errors.Is(outcomes[0].Err, ErrBatchAborted) || !errors.Is(outcomes[2].Err, ErrBatchAborted) {
+			t.Errorf("other operations: got %v and %v, want ErrBatchAborted", outcomes[0].Err, outcomes[2].Err)
+		}
+		if !errors.Is(outcomes[1].Err, ErrDuplicateEmail) {
+			t.Errorf("failed operation: got %v, want ErrDuplicateEmail", outcomes[1].Err)
+		}
+		users, _ := store.GetUsers(context.Background())
+		if len(users) != 0 || len(store.Events()) != 0 {
+			t.Errorf("store has %d users and %d events after a failed atomic batch, want none", len(users), len(store.Events()))
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// validateRequest applies the binding rules of a request struct, for requests that do not
+// arrive through a handler of their own, such as import rows and batch operations. The
+// error names each failing field.
+func validateRequest(req interface{}) error {
+	err := binding.Validator.ValidateStruct(req)
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+
+	messages := make([]string, 0, len(fieldErrors))
+	for _, fe := range fieldErrors {
+		field := strings.ToLower(fe.Field())
+		switch fe.Tag() {
+		case "required":
+			messages = append(messages, field+" is required")
+		case "email":
+			messages = append(messages, field+" must be an email address")
+		case "min":
+			messages = append(messages, fmt.Sprintf("%s must be at least %s characters", field, fe.Param()))
+		case "max":
+			messages = append(messages, fmt.Sprintf("%s must be at most %s characters", field, fe.Param()))
+		default:
+			messages = append(messages, field+" is invalid")
+		}
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
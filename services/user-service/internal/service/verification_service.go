@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/go-microservices/user-service/internal/auth"
+	"github.com/yourusername/go-microservices/user-service/internal/models"
+	"github.com/yourusername/go-microservices/user-service/internal/repository"
+)
+
+// verificationCooldown is how long a user has to wait before asking for another
+// verification link, so that a mailbox cannot be flooded
+const verificationCooldown = time.Minute
+
+var (
+	// ErrInvalidToken is returned when a token is unknown, expired or already used
+	ErrInvalidToken = errors.New("invalid or expired token")
+	// ErrAlreadyVerified is returned when requesting verification of a verified address
+	ErrAlreadyVerified = errors.New("email already verified")
+)
+
+// VerificationService handles email address verification
+type VerificationService struct {
+	users  *repository.UserRepository
+	repo   *repository.VerificationRepository
+	ttl    time.Duration
+	appURL string
+}
+
+// NewVerificationService creates a new VerificationService. Verification links point to
+// the verify-email page of the application at appURL.
+func NewVerificationService(users *repository.UserRepository, repo *repository.VerificationRepository, ttl time.Duration, appURL string) *VerificationService {
+	return &VerificationService{
+		users:  users,
+		repo:   repo,
+		ttl:    ttl,
+		appURL: appURL,
+	}
+}
+
+// Send issues a new verification link for the user's current address, invalidating
+// earlier ones. Links are issued at most once per verificationCooldown.
+func (s *VerificationService) Send(ctx context.Context, userID string) error {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrAlreadyVerified
+	}
+
+	now := time.Now()
+	issuedAt, err := s.repo.GetLastTokenTime(userID)
+	if err != nil {
+		return err
+	}
+	if issuedAt != nil && now.Sub(*issuedAt) < verificationCooldown {
+		return ErrTooManyRequests
+	}
+
+	token, event, err := s.prepare(user, now)
+	if err != nil {
+		return err
+	}
+	return s.repo.CreateToken(token, event)
+}
+
+// Verify consumes a verification token and marks the address it was issued for as verified
+func (s *VerificationService) Verify(token string) (*models.User, error) {
+	user, err := s.repo.ConsumeToken(auth.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidToken
+	}
+	return user, err
+}
+
+// prepare creates a verification token for the user's current address and the event
+// that delivers its link
+func (s *VerificationService) prepare(user *models.User, now time.Time) (*models.EmailVerificationToken, *models.UserEvent, error) {
+	plain, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token := &models.EmailVerificationToken{
+		TokenHash: hash,
+		UserID:    user.ID,
+		Email:     user.Email,
+		ExpiresAt: now.Add(s.ttl),
+		CreatedAt: now,
+	}
+	event := &models.UserEvent{
+		ID:     uuid.New().String(),
+		Type:   models.EventUserVerificationRequested,
+		UserID: user.ID,
+		Data: models.EventData{
+			Email: user.Email,
+			Name:  user.Name,
+			Link:  s.appURL + "/verify-email?token=" + url.QueryEscape(plain),
+		},
+		CreatedAt: now,
+	}
+	return token, event, nil
+}
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS user_events (
+    id UUID PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    user_id UUID NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_user_events_pending ON user_events(next_attempt_at) WHERE delivered_at IS NULL;
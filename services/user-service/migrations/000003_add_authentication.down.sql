@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS email_verification_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS email_verified_at,
+    DROP COLUMN IF EXISTS password_hash;
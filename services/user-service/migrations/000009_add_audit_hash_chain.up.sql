@@ -0,0 +1,69 @@
+ALTER TABLE audit_log
+    ADD COLUMN IF NOT EXISTS request_id VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS changes JSONB,
+    ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS hash VARCHAR(64);
+
+-- audit_log_hash chains an entry to the hash of the entry before it. Timestamps are
+-- rendered in UTC so the result does not depend on the session time zone.
+CREATE OR REPLACE FUNCTION audit_log_hash(prev_hash VARCHAR, entry audit_log) RETURNS VARCHAR AS $$
+    SELECT encode(sha256(convert_to(concat_ws(E'\x1f',
+        COALESCE(prev_hash, ''),
+        entry.id::TEXT,
+        COALESCE(entry.actor_id::TEXT, ''),
+        entry.action,
+        COALESCE(entry.target_id::TEXT, ''),
+        COALESCE(entry.ip, ''),
+        COALESCE(entry.request_id, ''),
+        entry.metadata::TEXT,
+        COALESCE(entry.changes::TEXT, ''),
+        to_char(entry.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+    ), 'UTF8')), 'hex')
+$$ LANGUAGE SQL STABLE;
+
+-- Chain the entries written before this migration in insertion order
+DO $$
+DECLARE
+    entry audit_log;
+    prev VARCHAR(64);
+BEGIN
+    FOR entry IN SELECT * FROM audit_log ORDER BY id LOOP
+        UPDATE audit_log SET prev_hash = prev, hash = audit_log_hash(prev, entry) WHERE id = entry.id;
+        SELECT hash INTO prev FROM audit_log WHERE id = entry.id;
+    END LOOP;
+END
+$$;
+
+ALTER TABLE audit_log ALTER COLUMN hash SET NOT NULL;
+
+-- audit_log_chain serializes writers until they commit and assigns the ID after taking
+-- the lock, so that IDs follow the chain and every entry links to the one before it
+CREATE OR REPLACE FUNCTION audit_log_chain() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('audit_log'));
+    NEW.id := nextval(pg_get_serial_sequence('audit_log', 'id'));
+    SELECT hash INTO NEW.prev_hash FROM audit_log ORDER BY id DESC LIMIT 1;
+    NEW.hash := audit_log_hash(NEW.prev_hash, NEW);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_chain
+    BEFORE INSERT ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_chain();
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS users_history_record ON users;
+DROP FUNCTION IF EXISTS users_history_record();
+DROP TABLE IF EXISTS users_history;
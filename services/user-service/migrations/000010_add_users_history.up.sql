@@ -0,0 +1,60 @@
+-- users_history holds every version of a user's profile, each valid from valid_from until
+-- valid_to. It has no foreign key to users, so versions outlive the user.
+CREATE TABLE IF NOT EXISTS users_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    operation VARCHAR(10) NOT NULL,
+    changed_fields TEXT[] NOT NULL DEFAULT '{}',
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    email_verified_at TIMESTAMP WITH TIME ZONE,
+    mfa_enabled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    valid_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    valid_to TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_users_history_user_id ON users_history(user_id, valid_from);
+
+-- Existing users start with their current version
+INSERT INTO users_history (user_id, operation, email, name, role, email_verified_at, mfa_enabled_at, created_at, updated_at, valid_from)
+SELECT id, 'insert', email, name, role, email_verified_at, mfa_enabled_at, created_at, updated_at, updated_at
+FROM users;
+
+-- users_history_record closes the current version of a user and opens the next one.
+-- Updates that leave the profile alone, such as password and MFA step changes, are
+-- skipped. Deleted users get a final 'delete' version with their last profile.
+CREATE OR REPLACE FUNCTION users_history_record() RETURNS TRIGGER AS $$
+DECLARE
+    changed TEXT[] := '{}';
+    entry users;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF NEW.email IS DISTINCT FROM OLD.email THEN changed := changed || 'email'; END IF;
+        IF NEW.name IS DISTINCT FROM OLD.name THEN changed := changed || 'name'; END IF;
+        IF NEW.role IS DISTINCT FROM OLD.role THEN changed := changed || 'role'; END IF;
+        IF NEW.email_verified_at IS DISTINCT FROM OLD.email_verified_at THEN changed := changed || 'email_verified_at'; END IF;
+        IF NEW.mfa_enabled_at IS DISTINCT FROM OLD.mfa_enabled_at THEN changed := changed || 'mfa_enabled_at'; END IF;
+        IF cardinality(changed) = 0 THEN
+            RETURN NULL;
+        END IF;
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        entry := OLD;
+    ELSE
+        entry := NEW;
+    END IF;
+
+    UPDATE users_history SET valid_to = now() WHERE user_id = entry.id AND valid_to IS NULL;
+    INSERT INTO users_history (user_id, operation, changed_fields, email, name, role, email_verified_at, mfa_enabled_at, created_at, updated_at, valid_from)
+    VALUES (entry.id, lower(TG_OP), changed, entry.email, entry.name, entry.role, entry.email_verified_at, entry.mfa_enabled_at, entry.created_at, entry.updated_at, now());
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_history_record
+    AFTER INSERT OR UPDATE OR DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION users_history_record();
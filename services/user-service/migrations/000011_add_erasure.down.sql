@@ -0,0 +1,54 @@
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION audit_log_chain() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('audit_log'));
+    NEW.id := nextval(pg_get_serial_sequence('audit_log', 'id'));
+    SELECT hash INTO NEW.prev_hash FROM audit_log ORDER BY id DESC LIMIT 1;
+    NEW.hash := audit_log_hash(NEW.prev_hash, NEW);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION audit_log_hash(prev_hash VARCHAR, entry audit_log) RETURNS VARCHAR AS $$
+    SELECT encode(sha256(convert_to(concat_ws(E'\x1f',
+        COALESCE(prev_hash, ''),
+        entry.id::TEXT,
+        COALESCE(entry.actor_id::TEXT, ''),
+        entry.action,
+        COALESCE(entry.target_id::TEXT, ''),
+        COALESCE(entry.ip, ''),
+        COALESCE(entry.request_id, ''),
+        entry.metadata::TEXT,
+        COALESCE(entry.changes::TEXT, ''),
+        to_char(entry.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+    ), 'UTF8')), 'hex')
+$$ LANGUAGE SQL STABLE;
+
+ALTER TABLE audit_log DISABLE TRIGGER audit_log_append_only;
+DO $$
+DECLARE
+    entry audit_log;
+    prev VARCHAR(64);
+BEGIN
+    FOR entry IN SELECT * FROM audit_log ORDER BY id LOOP
+        entry.prev_hash := prev;
+        entry.hash := audit_log_hash(prev, entry);
+        UPDATE audit_log SET prev_hash = entry.prev_hash, hash = entry.hash WHERE id = entry.id;
+        prev := entry.hash;
+    END LOOP;
+END
+$$;
+ALTER TABLE audit_log ENABLE TRIGGER audit_log_append_only;
+
+DROP FUNCTION IF EXISTS audit_log_detail_hash(audit_log);
+
+ALTER TABLE audit_log
+    DROP COLUMN IF EXISTS redacted_at,
+    DROP COLUMN IF EXISTS detail_hash;
+
+ALTER TABLE users DROP COLUMN IF EXISTS erased_at;
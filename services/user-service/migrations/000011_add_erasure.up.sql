@@ -0,0 +1,83 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS erased_at TIMESTAMP WITH TIME ZONE;
+
+-- Audit entries keep the client IP and the changed values, which are personal data that
+-- erasure has to remove. The chain now covers a hash of those details instead of the
+-- details themselves, so they can be redacted without breaking it.
+ALTER TABLE audit_log
+    ADD COLUMN IF NOT EXISTS detail_hash VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS redacted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE OR REPLACE FUNCTION audit_log_detail_hash(entry audit_log) RETURNS VARCHAR AS $$
+    SELECT encode(sha256(convert_to(concat_ws(E'\x1f',
+        COALESCE(entry.ip, ''),
+        COALESCE(entry.changes::TEXT, '')
+    ), 'UTF8')), 'hex')
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION audit_log_hash(prev_hash VARCHAR, entry audit_log) RETURNS VARCHAR AS $$
+    SELECT encode(sha256(convert_to(concat_ws(E'\x1f',
+        COALESCE(prev_hash, ''),
+        entry.id::TEXT,
+        COALESCE(entry.actor_id::TEXT, ''),
+        entry.action,
+        COALESCE(entry.target_id::TEXT, ''),
+        COALESCE(entry.request_id, ''),
+        entry.metadata::TEXT,
+        COALESCE(entry.detail_hash, ''),
+        to_char(entry.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+    ), 'UTF8')), 'hex')
+$$ LANGUAGE SQL STABLE;
+
+-- Rebuild the chain with the new hash
+ALTER TABLE audit_log DISABLE TRIGGER audit_log_append_only;
+DO $$
+DECLARE
+    entry audit_log;
+    prev VARCHAR(64);
+BEGIN
+    FOR entry IN SELECT * FROM audit_log ORDER BY id LOOP
+        entry.detail_hash := audit_log_detail_hash(entry);
+        entry.prev_hash := prev;
+        entry.hash := audit_log_hash(prev, entry);
+        UPDATE audit_log
+        SET detail_hash = entry.detail_hash, prev_hash = entry.prev_hash, hash = entry.hash
+        WHERE id = entry.id;
+        prev := entry.hash;
+    END LOOP;
+END
+$$;
+ALTER TABLE audit_log ENABLE TRIGGER audit_log_append_only;
+
+ALTER TABLE audit_log ALTER COLUMN detail_hash SET NOT NULL;
+
+CREATE OR REPLACE FUNCTION audit_log_chain() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('audit_log'));
+    NEW.id := nextval(pg_get_serial_sequence('audit_log', 'id'));
+    NEW.redacted_at := NULL;
+    NEW.detail_hash := audit_log_detail_hash(NEW);
+    SELECT hash INTO NEW.prev_hash FROM audit_log ORDER BY id DESC LIMIT 1;
+    NEW.hash := audit_log_hash(NEW.prev_hash, NEW);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- The only change allowed is redaction: clearing the IP or the changed values and
+-- setting redacted_at
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.redacted_at IS NOT NULL
+        AND (NEW.ip IS NULL OR NEW.ip = OLD.ip)
+        AND (NEW.changes IS NULL OR NEW.changes = OLD.changes)
+        AND (NEW.id, NEW.actor_id, NEW.action, NEW.target_id, NEW.request_id, NEW.metadata,
+             NEW.detail_hash, NEW.prev_hash, NEW.hash, NEW.created_at)
+            IS NOT DISTINCT FROM
+            (OLD.id, OLD.actor_id, OLD.action, OLD.target_id, OLD.request_id, OLD.metadata,
+             OLD.detail_hash, OLD.prev_hash, OLD.hash, OLD.created_at)
+    THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations of the user service, so that the binary
+// can apply them without the files on disk
+package migrations
+
+import "embed"
+
+// FS holds the numbered up and down migrations
+//
+//go:embed *.sql
+var FS embed.FS